@@ -17,12 +17,23 @@ limitations under the License.
 package config
 
 import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
 	"github.com/spf13/cobra"
 	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/kverify"
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/out"
 )
 
+var (
+	enableWait        bool
+	enableWaitTimeout time.Duration
+)
+
 var addonsEnableCmd = &cobra.Command{
 	Use:   "enable ADDON_NAME",
 	Short: "Enables the addon w/ADDON_NAME within minikube (example: minikube addons enable dashboard). For a list of available addons use: minikube addons list ",
@@ -36,10 +47,54 @@ var addonsEnableCmd = &cobra.Command{
 		if err != nil {
 			exit.WithError("enable failed", err)
 		}
+		if enableWait {
+			waitForAddon(addon, enableWaitTimeout)
+		}
 		out.T(out.AddonEnable, "The '{{.addonName}}' addon is enabled", out.V{"addonName": addon})
 	},
 }
 
+// waitForAddon waits for the addon's workloads to be reconciled, and for metrics-server to serve metrics, so that the addon works once enable returns.
+// Every wait shares the one timeout.
+func waitForAddon(addon string, timeout time.Duration) {
+	client, err := kapi.Client(ClusterFlagValue())
+	if err != nil {
+		glog.Warningf("unable to get client to wait for %s: %v", addon, err)
+		return
+	}
+	// the addon is only written to the config if the cluster is not running
+	if _, err := client.Discovery().ServerVersion(); err != nil {
+		glog.Infof("apiserver unreachable, not waiting for %s: %v", addon, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := kverify.VerifyAddonManagerReconciled(ctx, client, addon, timeout); err != nil {
+		out.WarningT("The '{{.addonName}}' addon has not been deployed yet: {{.error}}", out.V{"addonName": addon, "error": err})
+		return
+	}
+	if rc, err := kapi.ClientConfig(ClusterFlagValue()); err == nil {
+		if err := kverify.WaitForAddonCRDsEstablished(ctx, rc, addon, timeout); err != nil {
+			out.WarningT("The '{{.addonName}}' addon resources can not be created yet: {{.error}}", out.V{"addonName": addon, "error": err})
+			return
+		}
+	}
+	if err := kverify.WaitForAddonWebhooksReady(ctx, client, addon, timeout); err != nil {
+		out.WarningT("An admission webhook of the '{{.addonName}}' addon is not ready, so creating resources may fail: {{.error}}", out.V{"addonName": addon, "error": err})
+		return
+	}
+	if addon != "metrics-server" {
+		return
+	}
+	out.T(out.Waiting, "Waiting for metrics-server to serve metrics ...")
+	if err := kverify.WaitForMetricsServer(ctx, client, timeout); err != nil {
+		out.WarningT("metrics-server is not serving metrics yet: {{.error}}", out.V{"error": err})
+	}
+}
+
 func init() {
+	addonsEnableCmd.Flags().BoolVar(&enableWait, "wait", true, "Block until the addon's workloads are deployed and ready to use")
+	addonsEnableCmd.Flags().DurationVar(&enableWaitTimeout, "wait-timeout", kverify.DefaultWaitTimeout, "max time to wait for the addon to be ready")
 	AddonsCmd.AddCommand(addonsEnableCmd)
 }
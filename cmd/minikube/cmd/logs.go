@@ -70,7 +70,7 @@ var logsCmd = &cobra.Command{
 			return
 		}
 		if showProblems {
-			problems := logs.FindProblems(cr, bs, *co.Config, co.CP.Runner)
+			problems := logs.FindProblems(cr, bs, *co.Config, co.CP.Runner, logs.LookBackwardsCount)
 			logs.OutputProblems(problems, numberOfProblems)
 			return
 		}
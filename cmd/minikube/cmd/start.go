@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -43,6 +44,7 @@ import (
 	"github.com/spf13/viper"
 	cmdcfg "k8s.io/minikube/cmd/minikube/cmd/config"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/kapi"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/kverify"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
@@ -115,6 +117,11 @@ const (
 	dryRun                  = "dry-run"
 	interactive             = "interactive"
 	waitTimeout             = "wait-timeout"
+	waitBudget              = "wait-budget"
+	waitTimeouts            = "wait-timeouts"
+	apiRetryInterval        = "api-retry-interval"
+	verifyOutput            = "verify-output"
+	waitPods                = "wait-pods"
 	nativeSSH               = "native-ssh"
 	minUsableMem            = 1024 // Kubernetes will not start with less than 1GB
 	minRecommendedMem       = 2000 // Warn at no lower than existing configurations
@@ -173,7 +180,12 @@ func initMinikubeFlags() {
 	startCmd.Flags().String(networkPlugin, "", "The name of the network plugin.")
 	startCmd.Flags().Bool(enableDefaultCNI, false, "Enable the default CNI plugin (/etc/cni/net.d/k8s.conf). Used in conjunction with \"--network-plugin=cni\".")
 	startCmd.Flags().StringSlice(waitComponents, kverify.DefaultWaitList, fmt.Sprintf("comma separated list of kubernetes components to verify and wait for after starting a cluster. defaults to %q, available options: %q . other acceptable values are 'all' or 'none', 'true' and 'false'", strings.Join(kverify.DefaultWaitList, ","), strings.Join(kverify.AllComponentsList, ",")))
-	startCmd.Flags().Duration(waitTimeout, 6*time.Minute, "max time to wait per Kubernetes core services to be healthy.")
+	startCmd.Flags().Duration(waitTimeout, kverify.DefaultWaitTimeout, "max time to wait per Kubernetes core services to be healthy.")
+	startCmd.Flags().StringToString(waitTimeouts, nil, fmt.Sprintf("comma separated list of component=duration pairs, overriding --wait-timeout for those components, for example apiserver=2m,system_pods=10m. available components: %q", strings.Join(kverify.AllComponentsList, ",")))
+	startCmd.Flags().Duration(apiRetryInterval, 0, "base interval between API calls while waiting for Kubernetes core services. 0 uses the kubeadm default of 500ms.")
+	startCmd.Flags().Duration(waitBudget, 0, "max time to wait for all Kubernetes core services together to be healthy. 0 means no limit beyond --wait-timeout per service.")
+	startCmd.Flags().String(verifyOutput, "text", "format to show the result of verifying each Kubernetes core service in. One of 'text' or 'json'.")
+	startCmd.Flags().StringArray(waitPods, []string{}, "namespace/selector of pods to wait for to be running before returning, for example default/app=web. May be repeated.")
 	startCmd.Flags().Bool(nativeSSH, true, "Use native Golang SSH client (default true). Set to 'false' to use the command line 'ssh' command when accessing the docker machine. Useful for the machine drivers when they will not start with 'Waiting for SSH'.")
 	startCmd.Flags().Bool(autoUpdate, true, "If set, automatically updates drivers to the latest version. Defaults to true.")
 	startCmd.Flags().Bool(installAddons, true, "If set, install addons. Defaults to true.")
@@ -315,6 +327,8 @@ func runStart(cmd *cobra.Command, args []string) {
 	}
 
 	validateFlags(cmd, driverName)
+	// automated runs have no one to read problems as they are found
+	kverify.ProblemReporting.Quiet = !viper.GetBool(interactive)
 	validateUser(driverName)
 
 	// Download & update the driver, even in --download-only mode
@@ -386,11 +400,44 @@ func runStart(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	podSpecs, err := cmd.Flags().GetStringArray(waitPods)
+	if err != nil {
+		exit.WithError("Failed to read --wait-pods", err)
+	}
+	if err := waitForPods(cc.Name, podSpecs, viper.GetDuration(waitTimeout)); err != nil {
+		exit.WithError("Wait failed", err)
+	}
+
 	if err := showKubectlInfo(kubeconfig, k8sVersion, cc.Name); err != nil {
 		glog.Errorf("kubectl info: %v", err)
 	}
 }
 
+// waitForPods waits for the pods matching each --wait-pods spec to be running
+func waitForPods(kubeContext string, specs []string, timeout time.Duration) error {
+	if len(specs) == 0 {
+		return nil
+	}
+	client, err := kapi.Client(kubeContext)
+	if err != nil {
+		return errors.Wrap(err, "k8s client")
+	}
+	for _, spec := range specs {
+		ns, selector, err := kverify.ParsePodWaitSpec(spec)
+		if err != nil {
+			return err
+		}
+		out.T(out.WaitingPods, "Waiting for pods matching {{.selector}} in {{.namespace}} ...", out.V{"selector": selector.String(), "namespace": ns})
+		if err := kverify.WaitForNamespaceActive(context.Background(), client, ns, timeout); err != nil {
+			return err
+		}
+		if err := kverify.WaitForPodsRunning(context.Background(), client, ns, selector, 1, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func updateDriver(driverName string) {
 	v, err := version.GetSemverVersion()
 	if err != nil {
@@ -911,6 +958,85 @@ func validateFlags(cmd *cobra.Command, drvName string) {
 	}
 
 	validateRegistryMirror()
+	validateWaitComponents(cmd)
+	validateWaitTimeouts(cmd)
+
+	if f := viper.GetString(verifyOutput); f != "text" && f != "json" {
+		exit.UsageT("Invalid --verify-output value: {{.format}}. Valid values: 'text', 'json'", out.V{"format": f})
+	}
+
+	if viper.GetDuration(apiRetryInterval) < 0 {
+		exit.UsageT("Invalid --api-retry-interval value: {{.interval}} must not be negative", out.V{"interval": viper.GetDuration(apiRetryInterval)})
+	}
+}
+
+// validateWaitTimeouts fails loudly if --wait-timeouts names an unknown component or is not a positive duration
+func validateWaitTimeouts(cmd *cobra.Command) {
+	specs, err := cmd.Flags().GetStringToString(waitTimeouts)
+	if err != nil {
+		return
+	}
+	if _, err := parseWaitTimeouts(specs); err != nil {
+		exit.UsageT("Invalid --wait-timeouts value: {{.error}}", out.V{"error": err})
+	}
+}
+
+// interpretWaitTimeoutsFlag returns the per-component timeouts set by --wait-timeouts, or nil if there are none
+func interpretWaitTimeoutsFlag(cmd cobra.Command) map[string]time.Duration {
+	specs, err := cmd.Flags().GetStringToString(waitTimeouts)
+	if err != nil || len(specs) == 0 {
+		return nil
+	}
+	timeouts, err := parseWaitTimeouts(specs)
+	if err != nil {
+		glog.Warningf("The value for --wait-timeouts flag is invalid: %v. Moving on will use --wait-timeout for every component", err)
+		return nil
+	}
+	glog.Infof("Wait timeouts: %+v", timeouts)
+	return timeouts
+}
+
+// parseWaitTimeouts parses component=duration pairs into timeouts keyed by wait key
+func parseWaitTimeouts(specs map[string]string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for k, v := range specs {
+		components, err := kverify.ValidateWaitComponents([]string{k})
+		if err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "timeout for %s", k)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("timeout for %s must be positive, got %s", k, v)
+		}
+		// a group such as "extra" sets the timeout of each of its components
+		for c := range components {
+			timeouts[c] = d
+		}
+	}
+	return timeouts, nil
+}
+
+// validateWaitComponents fails loudly if --wait names an unknown component
+func validateWaitComponents(cmd *cobra.Command) {
+	if !cmd.Flags().Changed(waitComponents) {
+		return
+	}
+	waitFlags, err := cmd.Flags().GetStringSlice(waitComponents)
+	if err != nil {
+		return
+	}
+	if len(waitFlags) == 1 {
+		switch waitFlags[0] {
+		case "true", "false", "all", "none":
+			return
+		}
+	}
+	if _, err := kverify.ValidateWaitComponents(waitFlags); err != nil {
+		exit.UsageT("Invalid --wait value: {{.error}}", out.V{"error": err})
+	}
 }
 
 // This function validates if the --registry-mirror
@@ -1070,6 +1196,9 @@ func createNode(cmd *cobra.Command, k8sVersion, kubeNodeName, drvName, repositor
 		Nodes: []config.Node{cp},
 	}
 	cfg.VerifyComponents = interpretWaitFlag(*cmd)
+	cfg.WaitTimeouts = interpretWaitTimeoutsFlag(*cmd)
+	cfg.WaitBudget = viper.GetDuration(waitBudget)
+	cfg.APICallRetryInterval = viper.GetDuration(apiRetryInterval)
 	return cfg, cp, nil
 }
 
@@ -1230,19 +1359,10 @@ func interpretWaitFlag(cmd cobra.Command) map[string]bool {
 		}
 	}
 
-	waitComponents := kverify.NoComponents
-	for _, wc := range waitFlags {
-		seen := false
-		for _, valid := range kverify.AllComponentsList {
-			if wc == valid {
-				waitComponents[wc] = true
-				seen = true
-				continue
-			}
-		}
-		if !seen {
-			glog.Warningf("The value %q is invalid for --wait flag. valid options are %q", wc, strings.Join(kverify.AllComponentsList, ","))
-		}
+	waitComponents, err := kverify.ValidateWaitComponents(waitFlags)
+	if err != nil {
+		glog.Warningf("The value for --wait flag is invalid: %v. Moving on will use the default wait components: %+v", err, kverify.DefaultComponents)
+		return kverify.DefaultComponents
 	}
 	glog.Infof("Waiting for components: %+v", waitComponents)
 	return waitComponents
@@ -18,7 +18,9 @@ package cmd
 
 import (
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -210,3 +212,63 @@ func TestSuggestMemoryAllocation(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWaitTimeouts(t *testing.T) {
+	var tests = []struct {
+		description string
+		specs       map[string]string
+		want        map[string]time.Duration
+		wantErr     bool
+	}{
+		{
+			description: "per component",
+			specs:       map[string]string{"apiserver": "2m", "system_pods": "10m"},
+			want:        map[string]time.Duration{"apiserver": 2 * time.Minute, "system_pods": 10 * time.Minute},
+		},
+		{
+			description: "group",
+			specs:       map[string]string{"extra": "90s"},
+			want:        map[string]time.Duration{"apiserver": 90 * time.Second, "system_pods": 90 * time.Second, "node_ready": 90 * time.Second, "dns": 90 * time.Second, "storage_provisioner": 90 * time.Second},
+		},
+		{
+			description: "unknown component",
+			specs:       map[string]string{"apisrver": "2m"},
+			wantErr:     true,
+		},
+		{
+			description: "invalid duration",
+			specs:       map[string]string{"apiserver": "2"},
+			wantErr:     true,
+		},
+		{
+			description: "zero duration",
+			specs:       map[string]string{"apiserver": "0s"},
+			wantErr:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := parseWaitTimeouts(test.specs)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseWaitTimeouts() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) && !test.wantErr {
+				t.Errorf("parseWaitTimeouts() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGenerateCfgFromFlagsAPIRetryInterval(t *testing.T) {
+	viper.SetDefault(humanReadableDiskSize, defaultDiskSize)
+	viper.Set(apiRetryInterval, 2*time.Second)
+	defer viper.Set(apiRetryInterval, time.Duration(0))
+
+	config, _, err := generateCfgFromFlags(&cobra.Command{}, constants.NewestKubernetesVersion, "none")
+	if err != nil {
+		t.Fatalf("Got unexpected error %v during config generation", err)
+	}
+	if config.APICallRetryInterval != 2*time.Second {
+		t.Errorf("APICallRetryInterval = %s, want 2s", config.APICallRetryInterval)
+	}
+}
@@ -56,6 +56,8 @@ const (
 	Nonexistent = "Nonexistent" // ~state.None
 	// Irrelevant is used for statuses that aren't meaningful for worker nodes
 	Irrelevant = "Irrelevant"
+	// Unhealthy means the process is running, but failing its health check
+	Unhealthy = "Unhealthy" // ~state.Error
 )
 
 // Status holds string representations of component states
@@ -66,6 +68,8 @@ type Status struct {
 	APIServer  string
 	Kubeconfig string
 	Worker     bool
+	// Components is the health of each cluster component, only checked for JSON output
+	Components *kverify.HealthReport `json:",omitempty"`
 }
 
 const (
@@ -208,13 +212,17 @@ func status(api libmachine.API, cc config.ClusterConfig, n config.Node) (*Status
 		return st, err
 	}
 
-	stk, err := kverify.KubeletStatus(cr)
+	hc := &kverify.HealthCheck{Runner: cr, Config: cc}
+	stk, err := hc.Kubelet()
 	glog.Infof("%s kubelet status = %s (err=%v)", name, stk, err)
 
-	if err != nil {
+	switch {
+	case err == kverify.ErrUnhealthy:
+		st.Kubelet = Unhealthy
+	case err != nil:
 		glog.Warningf("kubelet err: %v", err)
 		st.Kubelet = state.Error.String()
-	} else {
+	default:
 		st.Kubelet = stk.String()
 	}
 
@@ -235,8 +243,10 @@ func status(api libmachine.API, cc config.ClusterConfig, n config.Node) (*Status
 		}
 	}
 
-	sta, err := kverify.APIServerStatus(cr, hostname, port)
-	glog.Infof("%s apiserver status = %s (err=%v)", name, stk, err)
+	hc.Hostname = hostname
+	hc.Port = port
+	sta, err := hc.APIServer()
+	glog.Infof("%s apiserver status = %s (err=%v)", name, sta, err)
 
 	if err != nil {
 		glog.Errorln("Error apiserver status:", err)
@@ -245,6 +255,9 @@ func status(api libmachine.API, cc config.ClusterConfig, n config.Node) (*Status
 		st.APIServer = sta.String()
 	}
 
+	if strings.ToLower(output) == "json" {
+		st.Components = hc.Report()
+	}
 	return st, nil
 }
 
@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"testing"
+
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/kverify"
 )
 
 func TestExitCode(t *testing.T) {
@@ -89,6 +91,12 @@ func TestStatusJSON(t *testing.T) {
 		{"ok", &Status{Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: Configured}},
 		{"paused", &Status{Host: "Running", Kubelet: "Stopped", APIServer: "Paused", Kubeconfig: Configured}},
 		{"down", &Status{Host: "Stopped", Kubelet: "Stopped", APIServer: "Stopped", Kubeconfig: Misconfigured}},
+		{"degraded", &Status{Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: Configured, Components: &kverify.HealthReport{
+			APIServer:  kverify.ComponentHealth{State: "Running"},
+			Kubelet:    kverify.ComponentHealth{State: "Running"},
+			SystemPods: kverify.ComponentHealth{State: "Running"},
+			DNS:        kverify.ComponentHealth{State: "Error", Error: "no address in answer"},
+		}}},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -102,6 +110,9 @@ func TestStatusJSON(t *testing.T) {
 			if err := json.Unmarshal(b.Bytes(), st); err != nil {
 				t.Errorf("json(%+v) unmarshal error: %v", tc.state, err)
 			}
+			if (st.Components == nil) != (tc.state.Components == nil) || (st.Components != nil && *st.Components != *tc.state.Components) {
+				t.Errorf("json(%+v) components = %+v, want %+v", tc.state, st.Components, tc.state.Components)
+			}
 		})
 	}
 }
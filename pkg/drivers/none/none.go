@@ -17,6 +17,7 @@ limitations under the License.
 package none
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 
@@ -142,7 +143,7 @@ func (d *Driver) GetState() (state.State, error) {
 		return state.Running, nil
 	}
 
-	return kverify.KubeletStatus(d.exec)
+	return kverify.KubeletStatus(context.Background(), d.exec)
 }
 
 // Kill stops a host forcefully, including any containers that we are managing.
@@ -67,3 +67,24 @@ func parseFeatureArgs(featureGates string) (map[string]bool, string, error) {
 	componentFeatureArgs = strings.TrimRight(componentFeatureArgs, ",")
 	return kubeadmFeatureArgs, componentFeatureArgs, nil
 }
+
+// ComponentFeatureGates returns the feature gates which are passed to the kubernetes components rather than to kubeadm, with their values
+func ComponentFeatureGates(featureGates string) (map[string]bool, error) {
+	_, componentFeatureArgs, err := parseFeatureArgs(featureGates)
+	if err != nil {
+		return nil, err
+	}
+	gates := map[string]bool{}
+	for _, s := range strings.Split(componentFeatureArgs, ",") {
+		fg := strings.SplitN(s, "=", 2)
+		if len(fg) != 2 {
+			continue
+		}
+		v, err := strconv.ParseBool(strings.TrimSpace(fg[1]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert bool value \"%v\"", fg[1])
+		}
+		gates[strings.TrimSpace(fg[0])] = v
+	}
+	return gates, nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// addonLabel is the label identifying which addon an object belongs to
+	addonLabel = "kubernetes.io/minikube-addons"
+	// addonManagerModeLabel is the label telling the addon-manager how to reconcile an object
+	addonManagerModeLabel = "addonmanager.kubernetes.io/mode"
+)
+
+// addonWorkloadLabels maps each addon whose workloads are labeled with addonLabel to the value of that label
+var addonWorkloadLabels = map[string]string{
+	"dashboard":                   "dashboard",
+	"efk":                         "efk",
+	"freshpod":                    "freshpod",
+	"gvisor":                      "gvisor",
+	"helm-tiller":                 "helm",
+	"istio-provisioner":           "istio",
+	"logviewer":                   "logviewer",
+	"metrics-server":              "metrics-server",
+	"nvidia-driver-installer":     "nvidia-driver-installer",
+	"nvidia-gpu-device-plugin":    "nvidia-gpu-device-plugin",
+	"registry":                    "registry",
+	"registry-aliases":            "registry-aliases",
+	"registry-creds":              "registry-creds",
+	"storage-provisioner-gluster": "storage-provisioner-gluster",
+}
+
+// addonObject is a workload belonging to an addon, as much as is needed to tell whether it has been reconciled
+type addonObject struct {
+	// Name is the kind, namespace and name of the object
+	Name string
+	// Labels are the object's labels
+	Labels map[string]string
+	// Generation is the generation of the object's spec
+	Generation int64
+	// ObservedGeneration is the generation last observed by its controller, or Generation if it has none
+	ObservedGeneration int64
+}
+
+// VerifyAddonManagerReconciled waits for the addon's workloads to exist, labeled for the addon-manager, with their controllers having observed their latest spec.
+// Addons with no labeled workloads are not checked.
+func VerifyAddonManagerReconciled(ctx context.Context, cs kubernetes.Interface, addon string, timeout time.Duration) error {
+	value, ok := addonWorkloadLabels[addon]
+	if !ok {
+		glog.Infof("addon %s has no labeled workloads to verify", addon)
+		return nil
+	}
+	selector := fmt.Sprintf("%s=%s", addonLabel, value)
+	glog.Infof("waiting for addon %s workloads matching %q to be reconciled ...", addon, selector)
+	start := clk.Now()
+
+	last := "no workloads were found"
+	reconciled := func() (bool, error) {
+		objs, err := addonObjects(cs, selector)
+		if err != nil {
+			glog.Infof("temporary error listing addon %s workloads: %v", addon, err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := addonReconciled(objs)
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, reconciled); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "addon %s", addon)
+		}
+		return fmt.Errorf("addon %s was never reconciled: %s", addon, last)
+	}
+	glog.Infof("duration metric: took %s for addon %s to be reconciled ...", since(start), addon)
+	return nil
+}
+
+// addonObjects lists the workloads in every namespace matching selector
+func addonObjects(cs kubernetes.Interface, selector string) ([]addonObject, error) {
+	opts := meta.ListOptions{LabelSelector: selector}
+	objs := []addonObject{}
+
+	deps, err := cs.AppsV1().Deployments("").List(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployments")
+	}
+	for _, d := range deps.Items {
+		objs = append(objs, addonObject{Name: "deployment " + d.Namespace + "/" + d.Name, Labels: d.Labels, Generation: d.Generation, ObservedGeneration: d.Status.ObservedGeneration})
+	}
+	dss, err := cs.AppsV1().DaemonSets("").List(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "list daemonsets")
+	}
+	for _, d := range dss.Items {
+		objs = append(objs, addonObject{Name: "daemonset " + d.Namespace + "/" + d.Name, Labels: d.Labels, Generation: d.Generation, ObservedGeneration: d.Status.ObservedGeneration})
+	}
+	rcs, err := cs.CoreV1().ReplicationControllers("").List(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "list replicationcontrollers")
+	}
+	for _, r := range rcs.Items {
+		objs = append(objs, addonObject{Name: "replicationcontroller " + r.Namespace + "/" + r.Name, Labels: r.Labels, Generation: r.Generation, ObservedGeneration: r.Status.ObservedGeneration})
+	}
+	pods, err := cs.CoreV1().Pods("").List(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "list pods")
+	}
+	for _, p := range pods.Items {
+		// pods created by the workloads above are not addon objects themselves
+		if len(p.OwnerReferences) > 0 {
+			continue
+		}
+		objs = append(objs, addonObject{Name: "pod " + p.Namespace + "/" + p.Name, Labels: p.Labels, Generation: p.Generation, ObservedGeneration: p.Generation})
+	}
+	return objs, nil
+}
+
+// addonReconciled returns whether there are addon workloads, all labeled for the addon-manager and observed by their controllers, and a description of any which are not
+func addonReconciled(objs []addonObject) (bool, string) {
+	if len(objs) == 0 {
+		return false, "no workloads were found"
+	}
+	pending := []string{}
+	for _, o := range objs {
+		switch {
+		case o.Labels[addonManagerModeLabel] == "":
+			pending = append(pending, fmt.Sprintf("%s has no %s label", o.Name, addonManagerModeLabel))
+		case o.ObservedGeneration < o.Generation:
+			pending = append(pending, fmt.Sprintf("%s generation %d not yet observed", o.Name, o.Generation))
+		}
+	}
+	if len(pending) > 0 {
+		return false, strings.Join(pending, ", ")
+	}
+	return true, fmt.Sprintf("%d workloads reconciled", len(objs))
+}
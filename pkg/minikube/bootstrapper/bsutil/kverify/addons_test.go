@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import "testing"
+
+func TestAddonReconciled(t *testing.T) {
+	managed := map[string]string{addonLabel: "registry", addonManagerModeLabel: "Reconcile"}
+	var tests = []struct {
+		name string
+		objs []addonObject
+		want bool
+	}{
+		{"reconciled", []addonObject{{Name: "replicationcontroller kube-system/registry", Labels: managed, Generation: 1, ObservedGeneration: 1}}, true},
+		{"not created", nil, false},
+		{"not observed", []addonObject{{Name: "daemonset kube-system/registry-proxy", Labels: managed, Generation: 2, ObservedGeneration: 1}}, false},
+		{"unmanaged", []addonObject{{Name: "deployment kube-system/registry", Labels: map[string]string{addonLabel: "registry"}, Generation: 1, ObservedGeneration: 1}}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, msg := addonReconciled(tc.objs); got != tc.want {
+				t.Errorf("addonReconciled() = %v (%s), want %v", got, msg, tc.want)
+			}
+		})
+	}
+}
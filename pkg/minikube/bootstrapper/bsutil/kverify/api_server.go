@@ -18,8 +18,10 @@ limitations under the License.
 package kverify
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"os/exec"
@@ -31,7 +33,8 @@ import (
 	"github.com/docker/machine/libmachine/state"
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
-	"k8s.io/apimachinery/pkg/util/wait"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/kubernetes"
 	kconst "k8s.io/kubernetes/cmd/kubeadm/app/constants"
@@ -41,29 +44,93 @@ import (
 	"k8s.io/minikube/pkg/minikube/cruntime"
 )
 
+// WaitForAPIServerContainer waits for a running kube-apiserver container using only the container runtime, so it may be called before a client is available
+func WaitForAPIServerContainer(ctx context.Context, r cruntime.Manager, timeout time.Duration) error {
+	glog.Infof("waiting for apiserver container to be running ...")
+	start := clk.Now()
+
+	last := "containers were never listed"
+	running := func() (bool, error) {
+		id, err := runningContainerID(r, "kube-apiserver")
+		if err != nil {
+			last = err.Error()
+			return false, nil
+		}
+		glog.Infof("apiserver container is running: %s", id)
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, running); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "apiserver container")
+		}
+		return fmt.Errorf("apiserver container never started running: %s", last)
+	}
+	glog.Infof("duration metric: took %s for apiserver container to be running ...", since(start))
+	return nil
+}
+
+// WaitForAPIServerResponsive waits for the apiserver to serve a real API request, which unlike healthz requires etcd reads to work
+func WaitForAPIServerResponsive(ctx context.Context, cs kubernetes.Interface, timeout time.Duration) error {
+	glog.Infof("waiting for apiserver to serve API requests ...")
+	start := clk.Now()
+
+	var last error
+	responsive := func() (bool, error) {
+		if _, err := cs.CoreV1().Namespaces().Get("default", meta.GetOptions{}); err != nil {
+			glog.Infof("temporary error getting default namespace: %v", err)
+			observe(ctx, err.Error())
+			last = err
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, responsive); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "apiserver responsive")
+		}
+		return errors.Wrap(last, "apiserver never served API requests")
+	}
+	glog.Infof("duration metric: took %s for apiserver to serve API requests ...", since(start))
+	return nil
+}
+
 // WaitForAPIServerProcess waits for api server to be healthy returns error if it doesn't
-func WaitForAPIServerProcess(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr command.Runner, start time.Time, timeout time.Duration) error {
+func WaitForAPIServerProcess(ctx context.Context, r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr command.Runner, start time.Time, timeout time.Duration) error {
 	glog.Infof("waiting for apiserver process to appear ...")
-	err := wait.PollImmediate(time.Millisecond*500, timeout, func() (bool, error) {
-		if time.Since(start) > timeout {
+	err := pollImmediate(ctx, time.Millisecond*500, timeout, func() (bool, error) {
+		if since(start) > timeout {
 			return false, fmt.Errorf("cluster wait timed out during process check")
 		}
 
-		if time.Since(start) > minLogCheckTime {
-			announceProblems(r, bs, cfg, cr)
-			time.Sleep(kconst.APICallRetryInterval * 5)
+		if since(start) > minLogCheckTime {
+			if err := announceProblems(ctx, r, bs, cfg, cr, APIServerWaitKey, ProblemReporting); err != nil {
+				return false, err
+			}
+			if err := sleep(ctx, retryInterval(ctx)*5); err != nil {
+				return false, err
+			}
 		}
 
 		if _, ierr := apiServerPID(cr); ierr != nil {
+			observe(ctx, fmt.Sprintf("no apiserver process: %v", ierr))
 			return false, nil
 		}
 
 		return true, nil
 	})
 	if err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "apiserver process")
+		}
+		if isFatalProblem(err) {
+			return err
+		}
 		return fmt.Errorf("apiserver process never appeared")
 	}
-	glog.Infof("duration metric: took %s to wait for apiserver process to appear ...", time.Since(start))
+	glog.Infof("duration metric: took %s to wait for apiserver process to appear ...", since(start))
 	return nil
 }
 
@@ -78,57 +145,73 @@ func apiServerPID(cr command.Runner) (int, error) {
 }
 
 // WaitForHealthyAPIServer waits for api server status to be running
-func WaitForHealthyAPIServer(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr command.Runner, client *kubernetes.Clientset, start time.Time, hostname string, port int, timeout time.Duration) error {
+func WaitForHealthyAPIServer(ctx context.Context, r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr command.Runner, client kubernetes.Interface, start time.Time, hostname string, port int, timeout time.Duration) error {
 	glog.Infof("waiting for apiserver healthz status ...")
-	hStart := time.Now()
+	hStart := clk.Now()
 
 	healthz := func() (bool, error) {
-		if time.Since(start) > timeout {
+		if since(start) > timeout {
 			return false, fmt.Errorf("cluster wait timed out during healthz check")
 		}
 
-		if time.Since(start) > minLogCheckTime {
-			announceProblems(r, bs, cfg, cr)
-			time.Sleep(kconst.APICallRetryInterval * 5)
+		if since(start) > minLogCheckTime {
+			if err := announceProblems(ctx, r, bs, cfg, cr, APIServerWaitKey, ProblemReporting); err != nil {
+				return false, err
+			}
+			if err := sleep(ctx, retryInterval(ctx)*5); err != nil {
+				return false, err
+			}
 		}
 
-		status, err := apiServerHealthz(hostname, port)
+		status, err := apiServerHealthz(ctx, hostname, port)
 		if err != nil {
 			glog.Warningf("status: %v", err)
+			observe(ctx, fmt.Sprintf("healthz: %v", err))
 			return false, nil
 		}
 		if status != state.Running {
+			observe(ctx, fmt.Sprintf("healthz reports %s", status))
 			return false, nil
 		}
 		return true, nil
 	}
 
-	if err := wait.PollImmediate(kconst.APICallRetryInterval, kconst.DefaultControlPlaneTimeout, healthz); err != nil {
+	if err := pollImmediate(ctx, retryInterval(ctx), kconst.DefaultControlPlaneTimeout, healthz); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "apiserver healthz")
+		}
+		if isFatalProblem(err) {
+			return err
+		}
 		return fmt.Errorf("apiserver healthz never reported healthy")
 	}
 
 	vcheck := func() (bool, error) {
-		if time.Since(start) > timeout {
+		if since(start) > timeout {
 			return false, fmt.Errorf("cluster wait timed out during version check")
 		}
 		if err := APIServerVersionMatch(client, cfg.KubernetesConfig.KubernetesVersion); err != nil {
 			glog.Warningf("api server version match failed: %v", err)
+			observe(ctx, err.Error())
 			return false, nil
 		}
 		return true, nil
 	}
 
-	if err := wait.PollImmediate(kconst.APICallRetryInterval, kconst.DefaultControlPlaneTimeout, vcheck); err != nil {
+	if err := pollImmediate(ctx, retryInterval(ctx), kconst.DefaultControlPlaneTimeout, vcheck); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "apiserver version")
+		}
 		return fmt.Errorf("controlPlane never updated to %s", cfg.KubernetesConfig.KubernetesVersion)
 	}
 
-	glog.Infof("duration metric: took %s to wait for apiserver health ...", time.Since(hStart))
+	glog.Infof("duration metric: took %s to wait for apiserver health ...", since(hStart))
 	return nil
 }
 
 // APIServerVersionMatch checks if the server version matches the expected
-func APIServerVersionMatch(client *kubernetes.Clientset, expected string) error {
-	vi, err := client.ServerVersion()
+func APIServerVersionMatch(client kubernetes.Interface, expected string) error {
+	vi, err := client.Discovery().ServerVersion()
 	if err != nil {
 		return errors.Wrap(err, "server version")
 	}
@@ -153,7 +236,7 @@ func APIServerStatus(cr command.Runner, hostname string, port int) (state.State,
 	rr, err := cr.RunCmd(exec.Command("sudo", "egrep", "^[0-9]+:freezer:", fmt.Sprintf("/proc/%d/cgroup", pid)))
 	if err != nil {
 		glog.Warningf("unable to find freezer cgroup: %v", err)
-		return apiServerHealthz(hostname, port)
+		return apiServerHealthz(context.Background(), hostname, port)
 
 	}
 	freezer := strings.TrimSpace(rr.Stdout.String())
@@ -161,13 +244,13 @@ func APIServerStatus(cr command.Runner, hostname string, port int) (state.State,
 	fparts := strings.Split(freezer, ":")
 	if len(fparts) != 3 {
 		glog.Warningf("unable to parse freezer - found %d parts: %s", len(fparts), freezer)
-		return apiServerHealthz(hostname, port)
+		return apiServerHealthz(context.Background(), hostname, port)
 	}
 
 	rr, err = cr.RunCmd(exec.Command("sudo", "cat", path.Join("/sys/fs/cgroup/freezer", fparts[2], "freezer.state")))
 	if err != nil {
 		glog.Errorf("unable to get freezer state: %s", rr.Stderr.String())
-		return apiServerHealthz(hostname, port)
+		return apiServerHealthz(context.Background(), hostname, port)
 	}
 
 	fs := strings.TrimSpace(rr.Stdout.String())
@@ -175,25 +258,93 @@ func APIServerStatus(cr command.Runner, hostname string, port int) (state.State,
 	if fs == "FREEZING" || fs == "FROZEN" {
 		return state.Paused, nil
 	}
-	return apiServerHealthz(hostname, port)
+	return apiServerHealthz(context.Background(), hostname, port)
 }
 
-// apiServerHealthz hits the /healthz endpoint and returns libmachine style state.State
-func apiServerHealthz(hostname string, port int) (state.State, error) {
-	url := fmt.Sprintf("https://%s/healthz", net.JoinHostPort(hostname, fmt.Sprint(port)))
-	glog.Infof("Checking apiserver healthz at %s ...", url)
+// WaitForAPIServerHealthz waits for the apiserver /healthz endpoint to return "ok", tolerating connection errors until timeout
+func WaitForAPIServerHealthz(ctx context.Context, hostname string, port int, timeout time.Duration) error {
+	url := healthzURL(hostname, port)
+	glog.Infof("waiting for apiserver healthz at %s ...", url)
+	start := clk.Now()
+
+	healthz := func() (bool, error) {
+		resp, err := healthzGet(ctx, url)
+		// Connection refused, usually.
+		if err != nil {
+			glog.Infof("stopped: %s: %v", url, err)
+			observe(ctx, err.Error())
+			return false, nil
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			glog.Warningf("unable to read %s response: %v", url, err)
+			return false, nil
+		}
+		if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != "ok" {
+			glog.Infof("%s returned %d: %s", url, resp.StatusCode, body)
+			observe(ctx, fmt.Sprintf("%s returned %d: %s", url, resp.StatusCode, body))
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, healthz); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "apiserver healthz")
+		}
+		if isFatalProblem(err) {
+			return err
+		}
+		return fmt.Errorf("apiserver healthz at %s never returned ok", url)
+	}
+	glog.Infof("duration metric: took %s to wait for apiserver healthz ...", since(start))
+	return nil
+}
+
+// healthzURL returns the URL of the apiserver /healthz endpoint
+func healthzURL(hostname string, port int) string {
+	return fmt.Sprintf("https://%s/healthz", joinHostPort(hostname, port))
+}
+
+// joinHostPort returns hostname:port, bracketing IPv6 addresses whether or not hostname already is
+func joinHostPort(hostname string, port int) string {
+	return net.JoinHostPort(strings.TrimSuffix(strings.TrimPrefix(hostname, "["), "]"), strconv.Itoa(port))
+}
+
+// healthzTimeout is how long a single /healthz request may take, so that a hung apiserver does not block a wait
+const healthzTimeout = 5 * time.Second
+
+// healthzClient returns an HTTP client suitable for probing the apiserver /healthz endpoint
+func healthzClient() *http.Client {
 	// To avoid: x509: certificate signed by unknown authority
 	tr := &http.Transport{
 		Proxy:           nil, // To avoid connectiv issue if http(s)_proxy is set.
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 	}
-	client := &http.Client{Transport: tr}
-	resp, err := client.Get(url)
+	return &http.Client{Transport: tr, Timeout: healthzTimeout}
+}
+
+// healthzGet requests url, giving up once ctx is done or healthzTimeout has passed
+func healthzGet(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "new request")
+	}
+	return healthzClient().Do(req.WithContext(ctx))
+}
+
+// apiServerHealthz hits the /healthz endpoint and returns libmachine style state.State
+func apiServerHealthz(ctx context.Context, hostname string, port int) (state.State, error) {
+	url := healthzURL(hostname, port)
+	glog.Infof("Checking apiserver healthz at %s ...", url)
+	resp, err := healthzGet(ctx, url)
 	// Connection refused, usually.
 	if err != nil {
 		glog.Infof("stopped: %s: %v", url, err)
 		return state.Stopped, nil
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusUnauthorized {
 		glog.Errorf("%s returned code %d (unauthorized). Please ensure that your apiserver authorization settings make sense!", url, resp.StatusCode)
 		return state.Error, nil
@@ -204,3 +355,56 @@ func apiServerHealthz(hostname string, port int) (state.State, error) {
 	}
 	return state.Running, nil
 }
+
+// VerifyAPIServerAddress returns an error unless the apiserver advertises expected, as the address of the kubernetes service endpoints.
+// If it advertises another address, in-cluster clients and the kubeconfig may point at an address which is no longer reachable.
+func VerifyAPIServerAddress(cs kubernetes.Interface, expected string) error {
+	ep, err := cs.CoreV1().Endpoints("default").Get("kubernetes", meta.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "get kubernetes service endpoints")
+	}
+	advertised := endpointIPs(ep)
+	for _, ip := range advertised {
+		if ip == expected {
+			return nil
+		}
+	}
+	return fmt.Errorf("apiserver advertises %s, want %s: the host IP may have changed since the cluster was created", strings.Join(advertised, ", "), expected)
+}
+
+// WaitForAPIServerAddress waits for the apiserver to advertise expected as the address of the kubernetes service endpoints, as a restarted apiserver may still be advertising its previous address
+func WaitForAPIServerAddress(ctx context.Context, cs kubernetes.Interface, expected string, timeout time.Duration) error {
+	glog.Infof("waiting for apiserver to advertise %s ...", expected)
+	start := clk.Now()
+
+	var last error
+	advertised := func() (bool, error) {
+		if err := VerifyAPIServerAddress(cs, expected); err != nil {
+			glog.Infof("apiserver address not yet as expected: %v", err)
+			observe(ctx, err.Error())
+			last = err
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, advertised); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "apiserver address")
+		}
+		return last
+	}
+	glog.Infof("duration metric: took %s for apiserver to advertise %s ...", since(start), expected)
+	return nil
+}
+
+// endpointIPs returns the ready addresses across all subsets of ep
+func endpointIPs(ep *core.Endpoints) []string {
+	ips := []string{}
+	for _, s := range ep.Subsets {
+		for _, a := range s.Addresses {
+			ips = append(ips, a.IP)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+func TestHealthzURL(t *testing.T) {
+	var tests = []struct {
+		hostname string
+		want     string
+	}{
+		{"192.168.39.10", "https://192.168.39.10:8443/healthz"},
+		{"control-plane.minikube.internal", "https://control-plane.minikube.internal:8443/healthz"},
+		{"fd00::10", "https://[fd00::10]:8443/healthz"},
+		{"[fd00::10]", "https://[fd00::10]:8443/healthz"},
+		{"::1", "https://[::1]:8443/healthz"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.hostname, func(t *testing.T) {
+			if got := healthzURL(tc.hostname, 8443); got != tc.want {
+				t.Errorf("healthzURL(%q) = %q, want %q", tc.hostname, got, tc.want)
+			}
+		})
+	}
+
+	p := NewClientProvider(config.ClusterConfig{Name: "minikube"}, "fd00::10", 8443)
+	if want := "https://[fd00::10]:8443"; p.Endpoint != want {
+		t.Errorf("NewClientProvider() endpoint = %q, want %q", p.Endpoint, want)
+	}
+}
+
+func TestAPIServerHealthzCancelled(t *testing.T) {
+	unblock := make(chan struct{})
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer srv.Close()
+	defer close(unblock)
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("split %s: %v", srv.Listener.Addr(), err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("parse port %s: %v", port, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	st, err := apiServerHealthz(ctx, host, p)
+	if err != nil {
+		t.Fatalf("apiServerHealthz() error = %v", err)
+	}
+	if st != state.Stopped {
+		t.Errorf("apiServerHealthz() = %s, want %s", st, state.Stopped)
+	}
+	if d := time.Since(start); d >= healthzTimeout {
+		t.Errorf("apiServerHealthz() took %s, want it to give up once ctx is done", d)
+	}
+}
+
+func TestWaitForAPIServerContainer(t *testing.T) {
+	cr := command.NewFakeCommandRunner()
+	cr.SetCommandToOutput(map[string]string{
+		"docker ps --filter status=running --filter=name=k8s_kube-apiserver --format={{.ID}}": "b2\n",
+	})
+	r, err := cruntime.New(cruntime.Config{Type: "docker", Runner: cr})
+	if err != nil {
+		t.Fatalf("cruntime.New: %v", err)
+	}
+	if err := WaitForAPIServerContainer(context.Background(), r, time.Minute); err != nil {
+		t.Errorf("WaitForAPIServerContainer() = %v, want it to find the container with docker alone", err)
+	}
+}
+
+func TestEndpointIPs(t *testing.T) {
+	ep := &core.Endpoints{Subsets: []core.EndpointSubset{
+		{Addresses: []core.EndpointAddress{{IP: "192.168.39.10"}}, NotReadyAddresses: []core.EndpointAddress{{IP: "192.168.39.11"}}},
+		{Addresses: []core.EndpointAddress{{IP: "192.168.39.12"}}},
+	}}
+	if got, want := strings.Join(endpointIPs(ep), ","), "192.168.39.10,192.168.39.12"; got != want {
+		t.Errorf("endpointIPs() = %s, want %s", got, want)
+	}
+}
+
+func TestWaitForAPIServerAddress(t *testing.T) {
+	fc, restore := useFakeClock()
+	defer restore()
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		ip := "192.168.39.10"
+		if calls > 2 {
+			ip = "192.168.39.20"
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"kind":"Endpoints","apiVersion":"v1","metadata":{"name":"kubernetes","namespace":"default"},"subsets":[{"addresses":[{"ip":%q}]}]}`, ip)
+	}))
+	defer srv.Close()
+	cs, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatalf("NewForConfig: %v", err)
+	}
+
+	// the address changes on the third attempt
+	if err := WaitForAPIServerAddress(context.Background(), cs, "192.168.39.20", time.Minute); err != nil {
+		t.Errorf("WaitForAPIServerAddress() = %v, want nil once the new address is advertised", err)
+	}
+	if calls != 3 {
+		t.Errorf("WaitForAPIServerAddress() made %d requests, want 3", calls)
+	}
+
+	start := fc.Now()
+	err = WaitForAPIServerAddress(context.Background(), cs, "192.168.39.30", time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "apiserver advertises 192.168.39.20, want 192.168.39.30") {
+		t.Errorf("WaitForAPIServerAddress() = %v, want the advertised address", err)
+	}
+	if since(start) < time.Minute {
+		t.Errorf("WaitForAPIServerAddress() gave up after %s, want it to retry for the timeout", since(start))
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// apiServerManifest is the static pod manifest kubeadm writes for the apiserver
+var apiServerManifest = path.Join(vmpath.GuestManifestsDir, "kube-apiserver.yaml")
+
+// VerifyAuditEnabled returns an error unless the apiserver static pod manifest enables audit logging, and the audit policy file it names exists.
+// The policy file is checked on the node, which is where kubeadm mounts it into the apiserver pod from.
+func VerifyAuditEnabled(cr command.Runner) error {
+	rr, err := cr.RunCmd(exec.Command("sudo", "cat", apiServerManifest))
+	if err != nil {
+		return errors.Wrapf(err, "read %s", apiServerManifest)
+	}
+	flags := manifestFlags(rr.Stdout.String())
+
+	logPath := flags["audit-log-path"]
+	if logPath == "" {
+		return fmt.Errorf("audit logging is not enabled: --audit-log-path is not set in %s", apiServerManifest)
+	}
+	policy := flags["audit-policy-file"]
+	if policy == "" {
+		return fmt.Errorf("audit logging is not enabled: --audit-log-path is set, but --audit-policy-file is not")
+	}
+	if _, err := cr.RunCmd(exec.Command("sudo", "test", "-f", policy)); err != nil {
+		return errors.Wrapf(err, "audit policy file %s does not exist", policy)
+	}
+	glog.Infof("audit logging is enabled: logging to %s with policy %s", logPath, policy)
+	return nil
+}
+
+// manifestFlags returns the --key=value flags found in a static pod manifest, by key
+func manifestFlags(manifest string) map[string]string {
+	flags := map[string]string{}
+	for _, line := range strings.Split(manifest, "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "- ")
+		line = strings.Trim(line, `"'`)
+		if !strings.HasPrefix(line, "--") {
+			continue
+		}
+		kv := strings.SplitN(strings.TrimPrefix(line, "--"), "=", 2)
+		if len(kv) == 2 {
+			flags[kv[0]] = kv[1]
+		}
+	}
+	return flags
+}
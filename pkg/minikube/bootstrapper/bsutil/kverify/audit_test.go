@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestVerifyAuditEnabled(t *testing.T) {
+	manifest := func(flags ...string) string {
+		return "spec:\n  containers:\n  - command:\n    - kube-apiserver\n    - " + strings.Join(flags, "\n    - ") + "\n"
+	}
+	var tests = []struct {
+		name     string
+		manifest string
+		policy   bool
+		wantErr  bool
+	}{
+		{"enabled", manifest("--audit-log-path=/var/log/audit.log", "--audit-policy-file=/etc/ssl/certs/audit-policy.yaml"), true, false},
+		{"policy missing", manifest("--audit-log-path=/var/log/audit.log", "--audit-policy-file=/etc/ssl/certs/audit-policy.yaml"), false, true},
+		{"no policy flag", manifest("--audit-log-path=/var/log/audit.log"), true, true},
+		{"disabled", manifest("--secure-port=8443"), true, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmds := map[string]string{"sudo cat /etc/kubernetes/manifests/kube-apiserver.yaml": tc.manifest}
+			if tc.policy {
+				cmds["sudo test -f /etc/ssl/certs/audit-policy.yaml"] = ""
+			}
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(cmds)
+			err := VerifyAuditEnabled(cr)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("VerifyAuditEnabled() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
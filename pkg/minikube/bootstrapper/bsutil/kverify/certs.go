@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/util"
+)
+
+// certExpiryLayout is the layout of the EXPIRES column of `kubeadm certs check-expiration`
+const certExpiryLayout = "Jan 02, 2006 15:04 MST"
+
+// certExpiry is when a kubeadm managed certificate expires
+type certExpiry struct {
+	Name    string
+	Expires time.Time
+}
+
+// WaitForCertsValid returns an error naming any control plane certificate which expires within minRemaining
+func WaitForCertsValid(ctx context.Context, cr command.Runner, version string, minRemaining time.Duration) error {
+	sub, err := certsSubcommand(version)
+	if err != nil {
+		return err
+	}
+	c := exec.CommandContext(ctx, "/bin/bash", "-c", fmt.Sprintf("%s %s check-expiration", bsutil.InvokeKubeadm(version), sub))
+	rr, err := cr.RunCmd(c)
+	if err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "certs")
+		}
+		return errors.Wrapf(err, "check certificate expiration: %s", rr.Output())
+	}
+
+	deadline := clk.Now().Add(minRemaining)
+	expiring := []string{}
+	for _, ce := range parseCertExpiration(rr.Stdout.String()) {
+		if ce.Expires.Before(deadline) {
+			expiring = append(expiring, fmt.Sprintf("%s expires %s", ce.Name, ce.Expires.Format(certExpiryLayout)))
+		}
+	}
+	if len(expiring) > 0 {
+		return fmt.Errorf("certificates expire within %s: %s", minRemaining, strings.Join(expiring, ", "))
+	}
+	glog.Infof("no certificates expire within %s", minRemaining)
+	return nil
+}
+
+// certsSubcommand returns the kubeadm subcommand managing certificates, which graduated from alpha in v1.20
+func certsSubcommand(version string) (string, error) {
+	v, err := util.ParseKubernetesVersion(version)
+	if err != nil {
+		return "", errors.Wrap(err, "parse kubernetes version")
+	}
+	if v.LT(semver.MustParse("1.20.0-alpha.0")) {
+		return "alpha certs", nil
+	}
+	return "certs", nil
+}
+
+// parseCertExpiration parses the certificate rows of `kubeadm certs check-expiration` output, skipping anything else
+func parseCertExpiration(out string) []certExpiry {
+	certs := []certExpiry{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		// NAME Mon DD, YYYY HH:MM TZ ...
+		if len(fields) < 6 {
+			continue
+		}
+		t, err := time.Parse(certExpiryLayout, strings.Join(fields[1:6], " "))
+		if err != nil {
+			continue
+		}
+		certs = append(certs, certExpiry{Name: fields[0], Expires: t})
+	}
+	return certs
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCertExpiration(t *testing.T) {
+	out := `[check-expiration] Reading configuration from the cluster...
+
+CERTIFICATE                EXPIRES                  RESIDUAL TIME   CERTIFICATE AUTHORITY   EXTERNALLY MANAGED
+admin.conf                 Apr 01, 2021 00:00 UTC   364d                                    no
+apiserver                  Apr 02, 2020 12:30 UTC   1d              ca                      no
+
+CERTIFICATE AUTHORITY   EXPIRES                  RESIDUAL TIME   EXTERNALLY MANAGED
+ca                      Mar 30, 2030 00:00 UTC   9y              no
+`
+	got := parseCertExpiration(out)
+	want := []certExpiry{
+		{"admin.conf", time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"apiserver", time.Date(2020, 4, 2, 12, 30, 0, 0, time.UTC)},
+		{"ca", time.Date(2030, 3, 30, 0, 0, 0, 0, time.UTC)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseCertExpiration() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || !got[i].Expires.Equal(want[i].Expires) {
+			t.Errorf("parseCertExpiration()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
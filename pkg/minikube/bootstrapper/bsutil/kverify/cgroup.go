@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// kubeletConfigPath is where kubeadm writes the kubelet configuration within the guest
+const kubeletConfigPath = "/var/lib/kubelet/config.yaml"
+
+// kubeletCgroupDriverRe matches the cgroup driver in the kubelet configuration
+var kubeletCgroupDriverRe = regexp.MustCompile(`(?m)^cgroupDriver:\s*(\S+)`)
+
+// crioCgroupManagerRe matches the cgroup manager in the CRI-O configuration
+var crioCgroupManagerRe = regexp.MustCompile(`(?m)^\s*cgroup_manager\s*=\s*"(\S+)"`)
+
+// containerdSystemdCgroupRe matches whether containerd uses the systemd cgroup driver
+var containerdSystemdCgroupRe = regexp.MustCompile(`(?m)^\s*(?:systemd_cgroup|SystemdCgroup)\s*=\s*(true|false)`)
+
+// VerifyCgroupDriver returns an error if the kubelet and container runtime are configured with different cgroup drivers
+func VerifyCgroupDriver(cr command.Runner, runtime string) error {
+	kd, rd := kubeletCgroupDriver(cr), runtimeCgroupDriver(cr, runtime)
+	if kd == "" || rd == "" {
+		glog.Infof("unable to determine the kubelet or %s cgroup driver, skipping cgroup driver check", runtime)
+		return nil
+	}
+	if kd != rd {
+		return fmt.Errorf("the kubelet uses the %q cgroup driver, but %s uses %q", kd, runtime, rd)
+	}
+	glog.Infof("kubelet and %s both use the %q cgroup driver", runtime, kd)
+	return nil
+}
+
+// kubeletCgroupDriver returns the cgroup driver from the kubelet configuration, or "" if its configuration is unreadable
+func kubeletCgroupDriver(cr command.Runner) string {
+	rr, err := cr.RunCmd(exec.Command("sudo", "cat", kubeletConfigPath))
+	if err != nil {
+		glog.Infof("unable to read kubelet config: %v", err)
+		return ""
+	}
+	if d := parseKubeletCgroupDriver(rr.Stdout.String()); d != "" {
+		return d
+	}
+	// the kubelet defaults to cgroupfs
+	return "cgroupfs"
+}
+
+// parseKubeletCgroupDriver returns the cgroup driver from kubelet configuration contents, or "" if unset
+func parseKubeletCgroupDriver(conf string) string {
+	m := kubeletCgroupDriverRe.FindStringSubmatch(conf)
+	if m == nil {
+		return ""
+	}
+	return strings.Trim(m[1], `"'`)
+}
+
+// dockerCgroupDriver returns the cgroup driver used by docker, or "" if unknown or docker is not running
+func dockerCgroupDriver(cr command.Runner) string {
+	rr, err := cr.RunCmd(exec.Command("docker", "info", "--format", "{{.CgroupDriver}}"))
+	if err != nil {
+		glog.Infof("unable to get docker cgroup driver: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(rr.Stdout.String())
+}
+
+// runtimeCgroupDriver returns the cgroup driver the container runtime is configured with, or "" if unknown
+func runtimeCgroupDriver(cr command.Runner, runtime string) string {
+	switch runtime {
+	case "docker", "":
+		return dockerCgroupDriver(cr)
+	case "crio", "cri-o":
+		return parseCrioCgroupManager(catFile(cr, "/etc/crio/crio.conf"))
+	case "containerd":
+		return parseContainerdCgroupDriver(catFile(cr, "/etc/containerd/config.toml"))
+	}
+	return ""
+}
+
+// catFile returns the contents of a file within the guest, or "" if it can not be read
+func catFile(cr command.Runner, path string) string {
+	rr, err := cr.RunCmd(exec.Command("sudo", "cat", path))
+	if err != nil {
+		glog.Infof("unable to read %s: %v", path, err)
+		return ""
+	}
+	return rr.Stdout.String()
+}
+
+// parseCrioCgroupManager returns the cgroup manager from CRI-O configuration contents, or "" if unset
+func parseCrioCgroupManager(conf string) string {
+	m := crioCgroupManagerRe.FindStringSubmatch(conf)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(m[1])
+}
+
+// parseContainerdCgroupDriver returns the cgroup driver from containerd configuration contents, or "" if its config was unreadable
+func parseContainerdCgroupDriver(conf string) string {
+	if conf == "" {
+		return ""
+	}
+	m := containerdSystemdCgroupRe.FindStringSubmatch(conf)
+	if m != nil && m[1] == "true" {
+		return "systemd"
+	}
+	return "cgroupfs"
+}
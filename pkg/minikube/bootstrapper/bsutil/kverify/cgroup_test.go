@@ -0,0 +1,52 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestVerifyCgroupDriver(t *testing.T) {
+	var tests = []struct {
+		name    string
+		runtime string
+		kubelet string
+		files   map[string]string
+		wantErr bool
+	}{
+		{"docker match", "docker", "cgroupDriver: systemd\n", map[string]string{"docker info --format {{.CgroupDriver}}": "systemd\n"}, false},
+		{"docker mismatch", "docker", "cgroupDriver: systemd\n", map[string]string{"docker info --format {{.CgroupDriver}}": "cgroupfs\n"}, true},
+		{"kubelet default", "docker", "kind: KubeletConfiguration\n", map[string]string{"docker info --format {{.CgroupDriver}}": "cgroupfs\n"}, false},
+		{"crio match", "crio", "cgroupDriver: cgroupfs\n", map[string]string{"sudo cat /etc/crio/crio.conf": "[crio.runtime]\ncgroup_manager = \"cgroupfs\"\n"}, false},
+		{"crio mismatch", "crio", "cgroupDriver: cgroupfs\n", map[string]string{"sudo cat /etc/crio/crio.conf": "[crio.runtime]\ncgroup_manager = \"systemd\"\n"}, true},
+		{"containerd default", "containerd", "cgroupDriver: cgroupfs\n", map[string]string{"sudo cat /etc/containerd/config.toml": "[plugins.cri]\n  systemd_cgroup = false\n"}, false},
+		{"containerd systemd", "containerd", "cgroupDriver: cgroupfs\n", map[string]string{"sudo cat /etc/containerd/config.toml": "[plugins.cri]\n  systemd_cgroup = true\n"}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(map[string]string{"sudo cat /var/lib/kubelet/config.yaml": tc.kubelet})
+			cr.SetCommandToOutput(tc.files)
+			err := VerifyCgroupDriver(cr, tc.runtime)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("VerifyCgroupDriver() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
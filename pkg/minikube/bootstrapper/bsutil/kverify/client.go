@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/proxy"
+)
+
+// ClientProvider lazily builds a clientset from a kubeconfig, and reuses it until a request fails without reaching the apiserver, such as after it restarted.
+// The failure only takes effect on the next call to Client: a wait such as WaitForCluster keeps using the clientset it was given until it returns.
+type ClientProvider struct {
+	// RESTConfig is used instead of the kubeconfig, if set
+	RESTConfig *rest.Config
+	// Kubeconfig is the path to the kubeconfig file. If empty, the default loading rules are used, which merge every file in $KUBECONFIG.
+	Kubeconfig string
+	// Context is the kubeconfig context to use
+	Context string
+	// Endpoint overrides the apiserver address found in the kubeconfig, if set
+	Endpoint string
+	// NoProxy bypasses any HTTP proxy, as the apiserver of a local cluster is not reachable through one
+	NoProxy bool
+
+	mu     sync.Mutex
+	client *kubernetes.Clientset
+	// generation counts the clientsets built, so that failures of an already replaced clientset are ignored
+	generation int
+	stale      bool
+}
+
+// NewClientProvider returns a ClientProvider for the cluster, talking to the apiserver at hostname:port
+func NewClientProvider(cfg config.ClusterConfig, hostname string, port int) *ClientProvider {
+	return &ClientProvider{
+		Context:  cfg.Name,
+		Endpoint: fmt.Sprintf("https://%s", joinHostPort(hostname, port)),
+		NoProxy:  true,
+	}
+}
+
+// NewKubeconfigClientProvider returns a ClientProvider for any cluster in a kubeconfig, such as one not managed by minikube.
+// An empty path uses the default kubeconfig loading rules, and an empty context uses the current context.
+func NewKubeconfigClientProvider(path string, context string) *ClientProvider {
+	return &ClientProvider{Kubeconfig: path, Context: context}
+}
+
+// NewRESTClientProvider returns a ClientProvider for the cluster described by rc
+func NewRESTClientProvider(rc *rest.Config) *ClientProvider {
+	return &ClientProvider{RESTConfig: rc}
+}
+
+// Client returns the cached clientset, building a new one if there is none or a request made with it failed to reach the apiserver
+func (p *ClientProvider) Client() (*kubernetes.Clientset, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client != nil && !p.stale {
+		return p.client, nil
+	}
+	if p.client != nil {
+		glog.Infof("apiserver connection failed, rebuilding client")
+	}
+
+	cc, err := p.restConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "client config")
+	}
+	if p.Endpoint != "" && cc.Host != p.Endpoint {
+		glog.Errorf("Overriding stale ClientConfig host %s with %s", cc.Host, p.Endpoint)
+		cc.Host = p.Endpoint
+	}
+	if p.NoProxy {
+		cc = proxy.UpdateTransport(cc)
+	}
+	gen := p.generation + 1
+	wt := cc.WrapTransport
+	cc.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wt != nil {
+			rt = wt(rt)
+		}
+		return &staleOnError{rt: rt, markStale: func(err error) { p.markStale(gen, err) }}
+	}
+	c, err := kubernetes.NewForConfig(cc)
+	if err != nil {
+		return nil, errors.Wrap(err, "new client")
+	}
+	p.client = c
+	p.generation = gen
+	p.stale = false
+	return c, nil
+}
+
+// restConfig returns a copy of RESTConfig if set, or else the config loaded from the kubeconfig
+func (p *ClientProvider) restConfig() (*rest.Config, error) {
+	if p.RESTConfig != nil {
+		return rest.CopyConfig(p.RESTConfig), nil
+	}
+	loader := clientcmd.NewDefaultClientConfigLoadingRules()
+	if p.Kubeconfig != "" {
+		loader.ExplicitPath = p.Kubeconfig
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loader, &clientcmd.ConfigOverrides{CurrentContext: p.Context}).ClientConfig()
+}
+
+// Invalidate drops the cached clientset, so that the next call to Client builds a new one
+func (p *ClientProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.client = nil
+	p.stale = false
+}
+
+// markStale records that a request made with the clientset of generation gen failed to reach the apiserver
+func (p *ClientProvider) markStale(gen int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if gen != p.generation || p.stale {
+		return
+	}
+	glog.Infof("apiserver request failed, the client will be rebuilt on next use: %v", err)
+	p.stale = true
+}
+
+// staleOnError calls markStale for requests which fail without a response, such as a refused connection or a certificate which changed with an apiserver restart
+type staleOnError struct {
+	rt        http.RoundTripper
+	markStale func(error)
+}
+
+// RoundTrip implements http.RoundTripper
+func (s *staleOnError) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := s.rt.RoundTrip(req)
+	// a cancelled request says nothing about the apiserver
+	if err != nil && req.Context().Err() == nil {
+		s.markStale(err)
+	}
+	return resp, err
+}
+
+// WrappedRoundTripper returns the wrapped http.RoundTripper
+func (s *staleOnError) WrappedRoundTripper() http.RoundTripper {
+	return s.rt
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestRESTClientProvider(t *testing.T) {
+	rc := &rest.Config{Host: "https://ci.example.com:6443"}
+	p := NewRESTClientProvider(rc)
+	if _, err := p.Client(); err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	if rc.WrapTransport != nil {
+		t.Errorf("Client() modified the caller's rest.Config")
+	}
+	if p.NoProxy {
+		t.Errorf("NewRESTClientProvider() bypasses the proxy, want it honored for external clusters")
+	}
+}
+
+func TestClientProviderRebuild(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "etcdserver: request timed out", http.StatusInternalServerError)
+	}))
+	p := NewRESTClientProvider(&rest.Config{Host: srv.URL})
+	c1, err := p.Client()
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+
+	if _, err := c1.Discovery().ServerVersion(); err == nil {
+		t.Fatalf("ServerVersion() succeeded, want an error response")
+	}
+	if c, _ := p.Client(); c != c1 {
+		t.Errorf("Client() rebuilt after the apiserver responded with an error, want it reused")
+	}
+
+	srv.Close()
+	if _, err := c1.Discovery().ServerVersion(); err == nil {
+		t.Fatalf("ServerVersion() succeeded, want the connection to fail")
+	}
+	c2, err := p.Client()
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	if c2 == c1 {
+		t.Errorf("Client() reused the clientset after its connection failed, want it rebuilt")
+	}
+
+	// a failure of the replaced clientset does not invalidate its replacement
+	_, _ = c1.Discovery().ServerVersion()
+	if c, _ := p.Client(); c != c2 {
+		t.Errorf("Client() rebuilt after a replaced clientset failed, want it reused")
+	}
+}
+
+func TestClientProviderKubeconfigList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	other := filepath.Join(dir, "other")
+	mk := filepath.Join(dir, "minikube")
+	if err := ioutil.WriteFile(other, []byte("apiVersion: v1\nkind: Config\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	cluster := `apiVersion: v1
+kind: Config
+clusters:
+- name: minikube
+  cluster:
+    server: https://192.168.39.10:8443
+contexts:
+- name: minikube
+  context:
+    cluster: minikube
+    user: minikube
+users:
+- name: minikube
+`
+	if err := ioutil.WriteFile(mk, []byte(cluster), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	defer os.Setenv("KUBECONFIG", os.Getenv("KUBECONFIG"))
+	os.Setenv("KUBECONFIG", other+string(filepath.ListSeparator)+mk)
+
+	// the context is only found by merging every file in $KUBECONFIG
+	p := NewClientProvider(config.ClusterConfig{Name: "minikube"}, "192.168.39.10", 8443)
+	cc, err := p.restConfig()
+	if err != nil {
+		t.Fatalf("restConfig() = %v, want the context from the second kubeconfig", err)
+	}
+	if cc.Host != "https://192.168.39.10:8443" {
+		t.Errorf("restConfig() host = %q, want %q", cc.Host, "https://192.168.39.10:8443")
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// DefaultMaxClockSkew is the most the guest clock may differ from the host before certificates and tokens are likely to be rejected
+const DefaultMaxClockSkew = time.Minute
+
+// authProblemRe matches problems which clock skew can cause, such as certificates which are not yet valid
+var authProblemRe = regexp.MustCompile(`x509|certificate has expired|not yet valid|[Uu]nauthorized|token.*expired`)
+
+// VerifyClockSkew returns an error if the guest clock differs from the host clock by more than maxSkew
+func VerifyClockSkew(cr command.Runner, maxSkew time.Duration) error {
+	skew, err := clockSkew(cr)
+	if err != nil {
+		return err
+	}
+	glog.Infof("guest clock skew: %s", skew)
+	if time.Duration(math.Abs(float64(skew))) > maxSkew {
+		return fmt.Errorf("guest clock is %s off from the host, more than %s", skew.Round(time.Second), maxSkew)
+	}
+	return nil
+}
+
+// clockSkew returns how far ahead of the host the guest clock is, negative if it is behind
+func clockSkew(cr command.Runner) (time.Duration, error) {
+	before := clk.Now()
+	rr, err := cr.RunCmd(exec.Command("date", "+%s.%N"))
+	if err != nil {
+		return 0, errors.Wrap(err, "guest date")
+	}
+	after := clk.Now()
+	guest, err := parseGuestTime(rr.Stdout.String())
+	if err != nil {
+		return 0, err
+	}
+	// assume the guest read its clock halfway through the round trip
+	host := before.Add(after.Sub(before) / 2)
+	return guest.Sub(host), nil
+}
+
+// parseGuestTime parses the output of `date +%s.%N`
+func parseGuestTime(out string) (time.Time, error) {
+	s := strings.TrimSpace(out)
+	parts := strings.SplitN(s, ".", 2)
+	sec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "parse guest time %q", s)
+	}
+	var nsec int64
+	if len(parts) == 2 {
+		// some date implementations do not support %N, and print it literally
+		if n, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+			nsec = n
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// hasAuthProblem returns whether any of the problems look like an authentication failure
+func hasAuthProblem(problems map[string][]string) bool {
+	for _, lines := range problems {
+		for _, l := range lines {
+			if authProblemRe.MatchString(l) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestVerifyClockSkew(t *testing.T) {
+	fc, restore := useFakeClock()
+	defer restore()
+
+	var tests = []struct {
+		name    string
+		date    string
+		wantErr bool
+	}{
+		{"in sync", fmt.Sprintf("%d.000000000\n", fc.now.Unix()), false},
+		{"slightly ahead", fmt.Sprintf("%d.500000000\n", fc.now.Unix()+20), false},
+		{"far behind", fmt.Sprintf("%d.000000000\n", fc.now.Unix()-3600), true},
+		{"no nanoseconds", fmt.Sprintf("%d.N\n", fc.now.Unix()), false},
+		{"garbage", "Thu Apr  1 00:00:00 UTC 2020\n", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(map[string]string{"date +%s.%N": tc.date})
+			err := VerifyClockSkew(cr, DefaultMaxClockSkew)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("VerifyClockSkew() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
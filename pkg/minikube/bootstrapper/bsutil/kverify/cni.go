@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// VerifyCNI waits for a kube-system pod on the pod network to be assigned an IP, which requires a working CNI.
+// Host network pods, such as the control plane, are assigned the node IP whether or not the CNI works.
+func VerifyCNI(ctx context.Context, cs kubernetes.Interface, timeout time.Duration) error {
+	glog.Infof("waiting for a pod network IP to be assigned ...")
+	start := clk.Now()
+
+	last := "pods were never listed"
+	networked := func() (bool, error) {
+		pods, err := cs.CoreV1().Pods("kube-system").List(meta.ListOptions{})
+		if err != nil {
+			glog.Infof("temporary error listing kube-system pods: %v", err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := podNetworkIP(pods.Items)
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, networked); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "cni")
+		}
+		return fmt.Errorf("no pod was ever assigned a pod network IP, the CNI may not be installed: %s", last)
+	}
+	glog.Infof("duration metric: took %s for a pod network IP to be assigned ...", since(start))
+	return nil
+}
+
+// podNetworkIP returns whether any of the pods has been assigned an IP on the pod network, and a description of the pods checked
+func podNetworkIP(pods []core.Pod) (bool, string) {
+	waiting := 0
+	for _, p := range pods {
+		if p.Spec.HostNetwork {
+			continue
+		}
+		if p.Status.PodIP != "" {
+			glog.Infof("pod %s has pod network IP %s", p.Name, p.Status.PodIP)
+			return true, fmt.Sprintf("%s has IP %s", p.Name, p.Status.PodIP)
+		}
+		waiting++
+	}
+	if waiting == 0 {
+		return false, "no pods on the pod network were found"
+	}
+	return false, fmt.Sprintf("%d pods on the pod network have no IP", waiting)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodNetworkIP(t *testing.T) {
+	apiserver := core.Pod{ObjectMeta: meta.ObjectMeta{Name: "kube-apiserver-minikube"}, Spec: core.PodSpec{HostNetwork: true}, Status: core.PodStatus{PodIP: "192.168.39.10"}}
+	pending := core.Pod{ObjectMeta: meta.ObjectMeta{Name: "coredns-a"}}
+	networked := core.Pod{ObjectMeta: meta.ObjectMeta{Name: "coredns-b"}, Status: core.PodStatus{PodIP: "10.244.0.2"}}
+	var tests = []struct {
+		name string
+		pods []core.Pod
+		want bool
+	}{
+		{"networked", []core.Pod{apiserver, pending, networked}, true},
+		{"no ip", []core.Pod{apiserver, pending}, false},
+		{"host network only", []core.Pod{apiserver}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, msg := podNetworkIP(tc.pods); got != tc.want {
+				t.Errorf("podNetworkIP() = %v (%s), want %v", got, msg, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForConfigMap waits for a ConfigMap to exist with non-empty data
+func WaitForConfigMap(ctx context.Context, cs kubernetes.Interface, ns string, name string, timeout time.Duration) error {
+	glog.Infof("waiting for configmap %s/%s ...", ns, name)
+	start := clk.Now()
+
+	last := "configmap was never found"
+	populated := func() (bool, error) {
+		cm, err := cs.CoreV1().ConfigMaps(ns).Get(name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting configmap %s/%s: %v", ns, name, err)
+			last = err.Error()
+			return false, nil
+		}
+		if len(cm.Data) == 0 && len(cm.BinaryData) == 0 {
+			last = "configmap has no data"
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, populated); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "configmap %s/%s", ns, name)
+		}
+		return fmt.Errorf("configmap %s/%s was never populated: %s", ns, name, last)
+	}
+	glog.Infof("duration metric: took %s for configmap %s/%s to be populated ...", since(start), ns, name)
+	return nil
+}
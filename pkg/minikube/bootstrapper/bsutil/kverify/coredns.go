@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForCoreDNSReplicas waits for every desired replica of the coredns deployment to be available.
+// If a replica can not be scheduled because of pod anti-affinity, as on a single node, the error recommends scaling down.
+func WaitForCoreDNSReplicas(ctx context.Context, cs kubernetes.Interface, timeout time.Duration) error {
+	glog.Infof("waiting for all coredns replicas to be available ...")
+	start := clk.Now()
+
+	last := "deployment was never found"
+	var stuck *core.Pod
+	available := func() (bool, error) {
+		d, err := cs.AppsV1().Deployments("kube-system").Get("coredns", meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting coredns deployment: %v", err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := deploymentAvailable(d)
+		last = msg
+		if ok {
+			return true, nil
+		}
+		pods, err := cs.CoreV1().Pods("kube-system").List(meta.ListOptions{LabelSelector: "k8s-app=kube-dns"})
+		if err != nil {
+			glog.Infof("temporary error listing coredns pods: %v", err)
+			return false, nil
+		}
+		stuck = unschedulableByAntiAffinity(pods.Items)
+		return false, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, available); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "coredns replicas")
+		}
+		if stuck != nil {
+			return fmt.Errorf("coredns pod %s can not be scheduled because of pod anti-affinity (%s); on a single node, try: kubectl -n kube-system scale deployment coredns --replicas=1", stuck.Name, last)
+		}
+		return fmt.Errorf("coredns replicas never became available: %s", last)
+	}
+	glog.Infof("duration metric: took %s for coredns replicas to be available ...", since(start))
+	return nil
+}
+
+// unschedulableByAntiAffinity returns the first Pending pod which the scheduler rejected because of pod anti-affinity, or nil if there is none
+func unschedulableByAntiAffinity(pods []core.Pod) *core.Pod {
+	for i, p := range pods {
+		if p.Status.Phase != core.PodPending {
+			continue
+		}
+		for _, c := range p.Status.Conditions {
+			if c.Type == core.PodScheduled && c.Status == core.ConditionFalse && c.Reason == core.PodReasonUnschedulable && strings.Contains(c.Message, "anti-affinity") {
+				return &pods[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUnschedulableByAntiAffinity(t *testing.T) {
+	running := core.Pod{ObjectMeta: meta.ObjectMeta{Name: "coredns-a"}, Status: core.PodStatus{Phase: core.PodRunning}}
+	affinity := core.Pod{ObjectMeta: meta.ObjectMeta{Name: "coredns-b"}, Status: core.PodStatus{Phase: core.PodPending, Conditions: []core.PodCondition{
+		{Type: core.PodScheduled, Status: core.ConditionFalse, Reason: core.PodReasonUnschedulable, Message: "0/1 nodes are available: 1 node(s) didn't match pod affinity/anti-affinity."},
+	}}}
+	resources := core.Pod{ObjectMeta: meta.ObjectMeta{Name: "coredns-c"}, Status: core.PodStatus{Phase: core.PodPending, Conditions: []core.PodCondition{
+		{Type: core.PodScheduled, Status: core.ConditionFalse, Reason: core.PodReasonUnschedulable, Message: "0/1 nodes are available: 1 Insufficient cpu."},
+	}}}
+
+	if got := unschedulableByAntiAffinity([]core.Pod{running, resources, affinity}); got == nil || got.Name != "coredns-b" {
+		t.Errorf("unschedulableByAntiAffinity() = %v, want coredns-b", got)
+	}
+	if got := unschedulableByAntiAffinity([]core.Pod{running, resources}); got != nil {
+		t.Errorf("unschedulableByAntiAffinity() = %v, want nil", got.Name)
+	}
+}
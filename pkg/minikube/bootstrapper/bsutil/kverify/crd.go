@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// WaitForCRDEstablished waits for a CustomResourceDefinition to have its names accepted and to be established, after which its custom resources may be created
+func WaitForCRDEstablished(ctx context.Context, rc *rest.Config, crdName string, timeout time.Duration) error {
+	glog.Infof("waiting for crd %s to be established ...", crdName)
+	start := clk.Now()
+
+	cs, err := clientset.NewForConfig(rc)
+	if err != nil {
+		return errors.Wrap(err, "apiextensions client")
+	}
+
+	last := "crd was never found"
+	established := func() (bool, error) {
+		crd, err := cs.ApiextensionsV1beta1().CustomResourceDefinitions().Get(crdName, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting crd %s: %v", crdName, err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := crdEstablished(crd)
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, established); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "crd %s", crdName)
+		}
+		return fmt.Errorf("crd %s was never established: %s", crdName, last)
+	}
+	glog.Infof("duration metric: took %s for crd %s to be established ...", since(start), crdName)
+	return nil
+}
+
+// WaitForAddonCRDsEstablished waits for each CustomResourceDefinition labeled as belonging to the addon to be established, so that its custom resources may be created
+func WaitForAddonCRDsEstablished(ctx context.Context, rc *rest.Config, addon string, timeout time.Duration) error {
+	value, ok := addonWorkloadLabels[addon]
+	if !ok {
+		return nil
+	}
+	cs, err := clientset.NewForConfig(rc)
+	if err != nil {
+		return errors.Wrap(err, "apiextensions client")
+	}
+	crds, err := cs.ApiextensionsV1beta1().CustomResourceDefinitions().List(meta.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", addonLabel, value)})
+	if err != nil {
+		return errors.Wrapf(err, "list addon %s crds", addon)
+	}
+	for _, crd := range crds.Items {
+		if err := WaitForCRDEstablished(ctx, rc, crd.Name, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// crdEstablished returns whether a CustomResourceDefinition has the NamesAccepted and Established conditions, and a description of those it lacks
+func crdEstablished(crd *apiextensions.CustomResourceDefinition) (bool, string) {
+	missing := []string{}
+	for _, ct := range []apiextensions.CustomResourceDefinitionConditionType{apiextensions.NamesAccepted, apiextensions.Established} {
+		c := crdCondition(crd, ct)
+		switch {
+		case c == nil:
+			missing = append(missing, fmt.Sprintf("%s not yet reported", ct))
+		case c.Status != apiextensions.ConditionTrue:
+			missing = append(missing, fmt.Sprintf("%s=%s (%s: %s)", c.Type, c.Status, c.Reason, c.Message))
+		}
+	}
+	if len(missing) > 0 {
+		return false, strings.Join(missing, "; ")
+	}
+	return true, "established"
+}
+
+// crdCondition returns the condition of a CustomResourceDefinition with the given type, or nil
+func crdCondition(crd *apiextensions.CustomResourceDefinition, ct apiextensions.CustomResourceDefinitionConditionType) *apiextensions.CustomResourceDefinitionCondition {
+	for i := range crd.Status.Conditions {
+		if crd.Status.Conditions[i].Type == ct {
+			return &crd.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+func TestCRDEstablished(t *testing.T) {
+	cond := func(ct apiextensions.CustomResourceDefinitionConditionType, st apiextensions.ConditionStatus) apiextensions.CustomResourceDefinitionCondition {
+		return apiextensions.CustomResourceDefinitionCondition{Type: ct, Status: st}
+	}
+	var tests = []struct {
+		name  string
+		conds []apiextensions.CustomResourceDefinitionCondition
+		want  bool
+	}{
+		{"established", []apiextensions.CustomResourceDefinitionCondition{cond(apiextensions.NamesAccepted, apiextensions.ConditionTrue), cond(apiextensions.Established, apiextensions.ConditionTrue)}, true},
+		{"not yet established", []apiextensions.CustomResourceDefinitionCondition{cond(apiextensions.NamesAccepted, apiextensions.ConditionTrue)}, false},
+		{"names conflict", []apiextensions.CustomResourceDefinitionCondition{cond(apiextensions.NamesAccepted, apiextensions.ConditionFalse), cond(apiextensions.Established, apiextensions.ConditionFalse)}, false},
+		{"new", nil, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			crd := &apiextensions.CustomResourceDefinition{Status: apiextensions.CustomResourceDefinitionStatus{Conditions: tc.conds}}
+			if got, msg := crdEstablished(crd); got != tc.want {
+				t.Errorf("crdEstablished() = %v (%s), want %v", got, msg, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+// criInfo is the subset of 'crictl info' output used to determine runtime readiness
+type criInfo struct {
+	Status struct {
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  bool   `json:"status"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// ContainerInfo describes the newest instance of a container, as reported by the container runtime
+type ContainerInfo struct {
+	// ID is the container ID
+	ID string
+	// State is the runtime state, such as CONTAINER_RUNNING or CONTAINER_EXITED
+	State string
+	// RestartCount is how many times the kubelet has restarted the container
+	RestartCount int
+	// Created is when the newest instance of the container was created
+	Created time.Time
+}
+
+// crictlContainers is the subset of 'crictl ps -o json' output used to build ContainerInfo
+type crictlContainers struct {
+	Containers []struct {
+		ID       string `json:"id"`
+		Metadata struct {
+			Name    string `json:"name"`
+			Attempt int    `json:"attempt"`
+		} `json:"metadata"`
+		State     string `json:"state"`
+		CreatedAt string `json:"createdAt"`
+	} `json:"containers"`
+}
+
+// APIServerContainerInfo returns the restart count and creation time of the apiserver container
+func APIServerContainerInfo(cr command.Runner) (*ContainerInfo, error) {
+	return containerInfo(cr, "kube-apiserver")
+}
+
+// ErrContainerInfoUnavailable is returned when container details can not be queried, as crictl is not installed
+var ErrContainerInfoUnavailable = errors.New("container info unavailable: crictl is not installed")
+
+// containerInfo returns information about the newest container with the given name.
+// Restart counts are only known to the CRI, so this requires crictl, which may be missing with the docker runtime.
+func containerInfo(cr command.Runner, name string) (*ContainerInfo, error) {
+	if _, err := cr.RunCmd(exec.Command("which", "crictl")); err != nil {
+		return nil, ErrContainerInfoUnavailable
+	}
+	rr, err := cr.RunCmd(exec.Command("sudo", "crictl", "ps", "-a", "-o", "json", fmt.Sprintf("--name=%s", name)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "crictl ps %s", name)
+	}
+	return parseContainerInfo(rr.Stdout.Bytes(), name)
+}
+
+// parseContainerInfo parses 'crictl ps -o json' output, returning the newest instance of the named container
+func parseContainerInfo(out []byte, name string) (*ContainerInfo, error) {
+	var cs crictlContainers
+	if err := json.Unmarshal(out, &cs); err != nil {
+		return nil, errors.Wrap(err, "parse crictl ps")
+	}
+
+	var newest *ContainerInfo
+	for _, c := range cs.Containers {
+		if c.Metadata.Name != name {
+			continue
+		}
+		ns, err := strconv.ParseInt(c.CreatedAt, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse createdAt %q", c.CreatedAt)
+		}
+		ci := &ContainerInfo{ID: c.ID, State: c.State, RestartCount: c.Metadata.Attempt, Created: time.Unix(0, ns)}
+		if newest == nil || ci.Created.After(newest.Created) {
+			newest = ci
+		}
+	}
+	if newest == nil {
+		return nil, fmt.Errorf("no %s container found", name)
+	}
+	return newest, nil
+}
+
+// WaitForCRI waits for the container runtime to report that it is ready to run containers
+func WaitForCRI(ctx context.Context, cr command.Runner, runtime string, timeout time.Duration) error {
+	glog.Infof("waiting for %s runtime to be ready ...", runtime)
+	start := clk.Now()
+
+	last := ""
+	ready := func() (bool, error) {
+		ok, out, err := criReady(cr, runtime)
+		last = out
+		if err != nil {
+			glog.Infof("%s not ready yet: %v", runtime, err)
+			return false, nil
+		}
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, ready); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "%s runtime", runtime)
+		}
+		return fmt.Errorf("%s runtime never became ready: %s", runtime, last)
+	}
+	glog.Infof("duration metric: took %s to wait for %s runtime ...", since(start), runtime)
+	return nil
+}
+
+// dockershimEndpoint is the CRI endpoint the kubelet serves for the docker runtime
+const dockershimEndpoint = "unix:///var/run/dockershim.sock"
+
+// criReady returns whether or not the runtime is ready, along with the output that decision was based on.
+// For docker, the daemon must answer 'docker info', and NetworkReady is read from dockershim, which the kubelet serves.
+func criReady(cr command.Runner, runtime string) (bool, string, error) {
+	args := []string{"sudo", "crictl", "info"}
+	if runtime == "" || runtime == "docker" {
+		rr, err := cr.RunCmd(exec.Command("docker", "info", "--format", "{{.ServerVersion}}"))
+		if err != nil {
+			return false, rr.Output(), errors.Wrap(err, "docker info")
+		}
+		args = []string{"sudo", "crictl", "--runtime-endpoint", dockershimEndpoint, "info"}
+	}
+
+	rr, err := cr.RunCmd(exec.Command(args[0], args[1:]...))
+	if err != nil {
+		return false, rr.Output(), errors.Wrap(err, "crictl info")
+	}
+	ok, err := parseCRIReady(rr.Stdout.Bytes())
+	return ok, rr.Output(), err
+}
+
+// parseCRIReady returns whether or not 'crictl info' output reports both RuntimeReady and NetworkReady
+func parseCRIReady(out []byte) (bool, error) {
+	var info criInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return false, errors.Wrap(err, "parse crictl info")
+	}
+
+	ready := map[string]bool{}
+	for _, c := range info.Status.Conditions {
+		ready[c.Type] = c.Status
+		if !c.Status {
+			glog.Infof("%s=false: %s %s", c.Type, c.Reason, strings.TrimSpace(c.Message))
+		}
+	}
+	return ready["RuntimeReady"] && ready["NetworkReady"], nil
+}
+
+// runningContainerID returns the ID of a running container with the given name, as listed by the container runtime
+func runningContainerID(r cruntime.Manager, name string) (string, error) {
+	ids, err := r.ListContainers(cruntime.ListOptions{State: cruntime.Running, Name: name})
+	if err != nil {
+		return "", errors.Wrapf(err, "list %s containers", name)
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("no running %s container found", name)
+	}
+	return ids[0], nil
+}
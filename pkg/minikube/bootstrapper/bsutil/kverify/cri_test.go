@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestParseCRIReady(t *testing.T) {
+	var tests = []struct {
+		name string
+		out  string
+		want bool
+	}{
+		{"ready", `{"status":{"conditions":[{"type":"RuntimeReady","status":true},{"type":"NetworkReady","status":true}]}}`, true},
+		{"network not ready", `{"status":{"conditions":[{"type":"RuntimeReady","status":true},{"type":"NetworkReady","status":false,"reason":"NetworkPluginNotReady"}]}}`, false},
+		{"no conditions", `{"status":{}}`, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCRIReady([]byte(tc.out))
+			if err != nil {
+				t.Fatalf("parseCRIReady: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("parseCRIReady(%s) = %v, want %v", tc.out, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCRIReady(t *testing.T) {
+	ready := `{"status":{"conditions":[{"type":"RuntimeReady","status":true},{"type":"NetworkReady","status":true}]}}`
+	noNetwork := `{"status":{"conditions":[{"type":"RuntimeReady","status":true},{"type":"NetworkReady","status":false,"reason":"NetworkPluginNotReady"}]}}`
+	var tests = []struct {
+		name    string
+		runtime string
+		cmds    map[string]string
+		want    bool
+		wantErr bool
+	}{
+		{"docker ready", "docker", map[string]string{
+			"docker info --format {{.ServerVersion}}":                             "19.03.8",
+			"sudo crictl --runtime-endpoint unix:///var/run/dockershim.sock info": ready,
+		}, true, false},
+		{"docker without network", "docker", map[string]string{
+			"docker info --format {{.ServerVersion}}":                             "19.03.8",
+			"sudo crictl --runtime-endpoint unix:///var/run/dockershim.sock info": noNetwork,
+		}, false, false},
+		{"docker daemon down", "", map[string]string{
+			"sudo crictl --runtime-endpoint unix:///var/run/dockershim.sock info": ready,
+		}, false, true},
+		{"containerd ready", "containerd", map[string]string{"sudo crictl info": ready}, true, false},
+		{"containerd without network", "containerd", map[string]string{"sudo crictl info": noNetwork}, false, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(tc.cmds)
+
+			got, _, err := criReady(cr, tc.runtime)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("criReady() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("criReady() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseContainerInfo(t *testing.T) {
+	out := `{"containers":[
+		{"id":"old","metadata":{"name":"kube-apiserver","attempt":1},"state":"CONTAINER_EXITED","createdAt":"1585699200000000000"},
+		{"id":"new","metadata":{"name":"kube-apiserver","attempt":2},"state":"CONTAINER_RUNNING","createdAt":"1585699260000000000"},
+		{"id":"other","metadata":{"name":"kube-apiserver-proxy","attempt":0},"state":"CONTAINER_RUNNING","createdAt":"1585699320000000000"}
+	]}`
+	got, err := parseContainerInfo([]byte(out), "kube-apiserver")
+	if err != nil {
+		t.Fatalf("parseContainerInfo: %v", err)
+	}
+	if got.ID != "new" || got.RestartCount != 2 || got.State != "CONTAINER_RUNNING" {
+		t.Errorf("parseContainerInfo() = %+v, want the newest kube-apiserver container", got)
+	}
+	if want := time.Unix(1585699260, 0); !got.Created.Equal(want) {
+		t.Errorf("Created = %s, want %s", got.Created, want)
+	}
+
+	if _, err := parseContainerInfo([]byte(`{"containers":[]}`), "kube-apiserver"); err == nil {
+		t.Errorf("parseContainerInfo() of no containers returned no error")
+	}
+}
+
+func TestAPIServerContainerInfoWithoutCrictl(t *testing.T) {
+	cr := command.NewFakeCommandRunner()
+	cr.SetCommandToOutput(map[string]string{"docker info --format {{.ServerVersion}}": "19.03.8"})
+	if _, err := APIServerContainerInfo(cr); err != ErrContainerInfoUnavailable {
+		t.Errorf("APIServerContainerInfo() error = %v, want %v", err, ErrContainerInfoUnavailable)
+	}
+}
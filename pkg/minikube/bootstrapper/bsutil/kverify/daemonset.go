@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	apps "k8s.io/api/apps/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForKubeProxy waits for the kube-proxy DaemonSet to be ready on every node
+func WaitForKubeProxy(ctx context.Context, cs kubernetes.Interface, timeout time.Duration) error {
+	return WaitForDaemonSetReady(ctx, cs, "kube-system", "kube-proxy", timeout)
+}
+
+// WaitForDaemonSetReady waits for a DaemonSet to have a ready pod on every node it is scheduled to
+func WaitForDaemonSetReady(ctx context.Context, cs kubernetes.Interface, ns string, name string, timeout time.Duration) error {
+	glog.Infof("waiting for daemonset %s/%s to be ready ...", ns, name)
+	start := clk.Now()
+
+	last := "daemonset was never found"
+	ready := func() (bool, error) {
+		ds, err := cs.AppsV1().DaemonSets(ns).Get(name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting daemonset %s/%s: %v", ns, name, err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := daemonSetReady(ds)
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, ready); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "daemonset %s/%s", ns, name)
+		}
+		return fmt.Errorf("daemonset %s/%s never became ready: %s", ns, name, last)
+	}
+	glog.Infof("duration metric: took %s for daemonset %s/%s to be ready ...", since(start), ns, name)
+	return nil
+}
+
+// daemonSetReady returns whether a DaemonSet has a ready, up to date pod on every node it is scheduled to, and a description of its status
+func daemonSetReady(ds *apps.DaemonSet) (bool, string) {
+	st := ds.Status
+	msg := fmt.Sprintf("%d/%d pods ready", st.NumberReady, st.DesiredNumberScheduled)
+	if st.ObservedGeneration < ds.Generation {
+		return false, fmt.Sprintf("%s, generation %d not yet observed", msg, ds.Generation)
+	}
+	if st.DesiredNumberScheduled == 0 {
+		return false, fmt.Sprintf("%s, not yet scheduled to any node", msg)
+	}
+	return st.NumberReady >= st.DesiredNumberScheduled && st.UpdatedNumberScheduled >= st.DesiredNumberScheduled, msg
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDaemonSetReady(t *testing.T) {
+	var tests = []struct {
+		name   string
+		status apps.DaemonSetStatus
+		gen    int64
+		want   bool
+	}{
+		{"ready", apps.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 2, NumberReady: 2, UpdatedNumberScheduled: 2}, 1, true},
+		{"lagging node", apps.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 2, NumberReady: 1, UpdatedNumberScheduled: 2}, 1, false},
+		{"rolling out", apps.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 2, NumberReady: 2, UpdatedNumberScheduled: 1}, 1, false},
+		{"generation not observed", apps.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 2, NumberReady: 2, UpdatedNumberScheduled: 2}, 2, false},
+		{"not scheduled", apps.DaemonSetStatus{ObservedGeneration: 1}, 1, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ds := &apps.DaemonSet{ObjectMeta: meta.ObjectMeta{Name: "kube-proxy", Generation: tc.gen}, Status: tc.status}
+			if got, msg := daemonSetReady(ds); got != tc.want {
+				t.Errorf("daemonSetReady() = %v (%s), want %v", got, msg, tc.want)
+			}
+		})
+	}
+}
@@ -18,9 +18,11 @@ limitations under the License.
 package kverify
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/cenkalti/backoff"
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,28 +31,56 @@ import (
 )
 
 // WaitForDefaultSA waits for the default service account to be created.
-func WaitForDefaultSA(cs *kubernetes.Clientset, timeout time.Duration) error {
-	glog.Info("waiting for default service account to be created ...")
-	start := time.Now()
+func WaitForDefaultSA(ctx context.Context, cs kubernetes.Interface, timeout time.Duration) error {
+	return WaitForDefaultServiceAccount(ctx, cs, "default", timeout)
+}
+
+// WaitForDefaultServiceAccount waits for the default service account in ns to be created and populated with secrets.
+func WaitForDefaultServiceAccount(ctx context.Context, cs kubernetes.Interface, ns string, timeout time.Duration) error {
+	glog.Infof("waiting for default service account in %q to be created ...", ns)
+	start := clk.Now()
+	secrets := []string{}
 	saReady := func() error {
+		if err := ctx.Err(); err != nil {
+			return backoff.Permanent(err)
+		}
 		// equivalent to manual check of 'kubectl --context profile get serviceaccount default'
-		sas, err := cs.CoreV1().ServiceAccounts("default").List(meta.ListOptions{})
+		sas, err := cs.CoreV1().ServiceAccounts(ns).List(meta.ListOptions{})
 		if err != nil {
 			glog.Infof("temproary error waiting for default SA: %v", err)
 			return err
 		}
 		for _, sa := range sas.Items {
-			if sa.Name == "default" {
-				glog.Infof("found service account: %q", sa.Name)
-				return nil
+			if sa.Name != "default" {
+				continue
+			}
+			if len(sa.Secrets) == 0 && len(sa.ImagePullSecrets) == 0 {
+				return fmt.Errorf("default service account in %q has no secrets yet", ns)
+			}
+			glog.Infof("found service account: %q", sa.Name)
+			secrets = secrets[:0]
+			for _, s := range sa.Secrets {
+				secrets = append(secrets, s.Name)
 			}
+			return nil
 		}
-		return fmt.Errorf("couldn't find default service account")
+		return fmt.Errorf("couldn't find default service account in %q", ns)
 	}
 	if err := retry.Expo(saReady, 500*time.Millisecond, timeout); err != nil {
-		return errors.Wrapf(err, "waited %s for SA", time.Since(start))
+		observe(ctx, err.Error())
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "default service account")
+		}
+		return errors.Wrapf(err, "waited %s for SA", since(start))
+	}
+
+	// older versions create token secrets asynchronously, and pods mounting them fail until they are populated
+	for _, name := range secrets {
+		if err := WaitForSecretPopulated(ctx, cs, ns, name, timeout-since(start)); err != nil {
+			return err
+		}
 	}
 
-	glog.Infof("duration metric: took %s for default service account to be created ...", time.Since(start))
+	glog.Infof("duration metric: took %s for default service account to be created ...", since(start))
 	return nil
 }
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForDeploymentAvailable waits for a deployment to have all of its desired replicas available
+func WaitForDeploymentAvailable(ctx context.Context, cs kubernetes.Interface, ns string, name string, timeout time.Duration) error {
+	glog.Infof("waiting for deployment %s/%s to be available ...", ns, name)
+	start := clk.Now()
+
+	last := "deployment was never found"
+	available := func() (bool, error) {
+		d, err := cs.AppsV1().Deployments(ns).Get(name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting deployment %s/%s: %v", ns, name, err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := deploymentAvailable(d)
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, available); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "deployment %s/%s", ns, name)
+		}
+		return fmt.Errorf("deployment %s/%s never became available: %s", ns, name, last)
+	}
+	glog.Infof("duration metric: took %s for deployment %s/%s to be available ...", since(start), ns, name)
+	return nil
+}
+
+// deploymentAvailable returns whether a deployment has rolled out all of its desired replicas, and a description of its status
+func deploymentAvailable(d *apps.Deployment) (bool, string) {
+	want := int32(1)
+	if d.Spec.Replicas != nil {
+		want = *d.Spec.Replicas
+	}
+	msg := fmt.Sprintf("%d/%d replicas available", d.Status.AvailableReplicas, want)
+	if d.Status.ObservedGeneration < d.Generation {
+		return false, fmt.Sprintf("%s, generation %d not yet observed", msg, d.Generation)
+	}
+
+	condition := false
+	for _, c := range d.Status.Conditions {
+		if c.Type == apps.DeploymentAvailable {
+			condition = c.Status == core.ConditionTrue
+			if !condition {
+				msg = fmt.Sprintf("%s, %s: %s", msg, c.Reason, c.Message)
+			}
+		}
+	}
+	return condition && d.Status.AvailableReplicas >= want && d.Status.UpdatedReplicas >= want, msg
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentAvailable(t *testing.T) {
+	replicas := int32(2)
+	available := apps.DeploymentCondition{Type: apps.DeploymentAvailable, Status: core.ConditionTrue}
+	unavailable := apps.DeploymentCondition{Type: apps.DeploymentAvailable, Status: core.ConditionFalse, Reason: "MinimumReplicasUnavailable", Message: "Deployment does not have minimum availability."}
+	var tests = []struct {
+		name   string
+		status apps.DeploymentStatus
+		gen    int64
+		want   bool
+	}{
+		{"available", apps.DeploymentStatus{ObservedGeneration: 1, AvailableReplicas: 2, UpdatedReplicas: 2, Conditions: []apps.DeploymentCondition{available}}, 1, true},
+		{"too few replicas", apps.DeploymentStatus{ObservedGeneration: 1, AvailableReplicas: 1, UpdatedReplicas: 2, Conditions: []apps.DeploymentCondition{available}}, 1, false},
+		{"condition false", apps.DeploymentStatus{ObservedGeneration: 1, AvailableReplicas: 2, UpdatedReplicas: 2, Conditions: []apps.DeploymentCondition{unavailable}}, 1, false},
+		{"rolling out", apps.DeploymentStatus{ObservedGeneration: 1, AvailableReplicas: 2, UpdatedReplicas: 2, Conditions: []apps.DeploymentCondition{available}}, 2, false},
+		{"no conditions", apps.DeploymentStatus{ObservedGeneration: 1, AvailableReplicas: 2, UpdatedReplicas: 2}, 1, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &apps.Deployment{
+				ObjectMeta: meta.ObjectMeta{Name: "app", Generation: tc.gen},
+				Spec:       apps.DeploymentSpec{Replicas: &replicas},
+				Status:     tc.status,
+			}
+			if got, msg := deploymentAvailable(d); got != tc.want {
+				t.Errorf("deploymentAvailable() = %v (%s), want %v", got, msg, tc.want)
+			}
+		})
+	}
+}
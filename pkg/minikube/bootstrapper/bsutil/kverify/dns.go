@@ -0,0 +1,179 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// dnsCheckName is the name resolved to verify cluster DNS
+	dnsCheckName = "kubernetes.default.svc.cluster.local"
+	// dnsCheckImage runs nslookup within the cluster. Later busybox releases have an nslookup which fails on search domains.
+	dnsCheckImage = "busybox:1.28"
+	// dnsCheckNamespace is where the check pod runs, out of the way of user workloads
+	dnsCheckNamespace = "kube-system"
+	// dnsCheckLabel selects the check pods, including any left behind by an interrupted check
+	dnsCheckLabel = "minikube.k8s.io/dns-check"
+	// dnsPodTimeout is how long a check pod may take to run to completion
+	dnsPodTimeout = time.Minute
+)
+
+// ErrDNSCheckUnavailable is returned when the DNS check pod is unable to run, such as when its image can not be pulled, so whether DNS works is unknown
+var ErrDNSCheckUnavailable = errors.New("dns check pod is unable to run")
+
+// WaitForDNSFunctional waits for a pod to resolve the kubernetes service through cluster DNS.
+// If the check pod is unable to run, an error wrapping ErrDNSCheckUnavailable is returned, and WaitForComponents reports DNS as skipped rather than failed.
+func WaitForDNSFunctional(ctx context.Context, cs kubernetes.Interface, timeout time.Duration) error {
+	glog.Infof("waiting for cluster DNS to resolve %s ...", dnsCheckName)
+	start := clk.Now()
+
+	last := "service was never found"
+	var unavailable error
+	resolves := func() (bool, error) {
+		err := checkDNS(ctx, cs)
+		if errors.Is(err, ErrDNSCheckUnavailable) {
+			unavailable = err
+			return true, nil
+		}
+		if err != nil {
+			glog.Infof("temporary error resolving %s: %v", dnsCheckName, err)
+			last = err.Error()
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, resolves); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "dns")
+		}
+		return fmt.Errorf("cluster DNS never resolved %s: %s", dnsCheckName, last)
+	}
+	if unavailable != nil {
+		return errors.Wrap(unavailable, "unable to verify cluster DNS")
+	}
+	glog.Infof("duration metric: took %s for cluster DNS to be functional ...", since(start))
+	return nil
+}
+
+// checkDNS runs a short-lived pod which resolves the kubernetes service, returning an error unless it gets an answer.
+// The lookup runs from within the pod network, so that CNI and network policy failures are caught.
+func checkDNS(ctx context.Context, cs kubernetes.Interface) error {
+	zero := int64(0)
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{GenerateName: "minikube-dns-check-", Labels: map[string]string{dnsCheckLabel: "true"}},
+		Spec: core.PodSpec{
+			RestartPolicy:                 core.RestartPolicyNever,
+			TerminationGracePeriodSeconds: &zero,
+			Tolerations:                   []core.Toleration{{Operator: core.TolerationOpExists}},
+			Containers: []core.Container{{
+				Name:    "nslookup",
+				Image:   dnsCheckImage,
+				Command: []string{"nslookup", dnsCheckName},
+			}},
+		},
+	}
+	pods := cs.CoreV1().Pods(dnsCheckNamespace)
+	// deleting by label also catches a pod whose create failed after it was stored, and those of interrupted checks
+	defer deleteDNSCheckPods(cs)
+	pod, err := pods.Create(pod)
+	if err != nil {
+		return errors.Wrap(err, "create dns check pod")
+	}
+
+	var perr error
+	finished := func() (bool, error) {
+		p, err := pods.Get(pod.Name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("get dns check pod %s: %v", pod.Name, err)
+			return false, nil
+		}
+		done, err := dnsPodFinished(*p)
+		perr = err
+		return done, nil
+	}
+	if err := pollImmediate(ctx, retryInterval(ctx), dnsPodTimeout, finished); err != nil {
+		return errors.Wrapf(err, "dns check pod %s never finished", pod.Name)
+	}
+	if perr != nil {
+		return perr
+	}
+
+	logs, err := pods.GetLogs(pod.Name, &core.PodLogOptions{}).DoRaw()
+	if err != nil {
+		return errors.Wrap(err, "dns check pod logs")
+	}
+	if !nslookupResolved(string(logs), dnsCheckName) {
+		return fmt.Errorf("no address in answer: %q", logs)
+	}
+	return nil
+}
+
+// deleteDNSCheckPods deletes every DNS check pod
+func deleteDNSCheckPods(cs kubernetes.Interface) {
+	zero := int64(0)
+	opts := meta.ListOptions{LabelSelector: dnsCheckLabel + "=true"}
+	if err := cs.CoreV1().Pods(dnsCheckNamespace).DeleteCollection(&meta.DeleteOptions{GracePeriodSeconds: &zero}, opts); err != nil {
+		glog.Warningf("unable to delete dns check pods: %v", err)
+	}
+}
+
+// dnsPodFinished returns whether the DNS check pod has run to completion, and ErrDNSCheckUnavailable if it is unable to run
+func dnsPodFinished(pod core.Pod) (bool, error) {
+	if pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+		return true, nil
+	}
+	for _, c := range pod.Status.ContainerStatuses {
+		w := c.State.Waiting
+		if w == nil {
+			continue
+		}
+		switch w.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+			return true, errors.Wrapf(ErrDNSCheckUnavailable, "%s: %s", w.Reason, w.Message)
+		}
+	}
+	return false, nil
+}
+
+// nslookupResolved returns whether nslookup output contains an address for name
+func nslookupResolved(out string, name string) bool {
+	answer := false
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Name:") {
+			answer = strings.TrimSpace(strings.TrimPrefix(line, "Name:")) == name
+			continue
+		}
+		if answer && strings.HasPrefix(line, "Address") {
+			return true
+		}
+	}
+	return false
+}
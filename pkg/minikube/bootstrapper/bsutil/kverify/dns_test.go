@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestNSLookupResolved(t *testing.T) {
+	var tests = []struct {
+		name string
+		out  string
+		want bool
+	}{
+		{"busybox", "Server:    10.96.0.10\nAddress 1: 10.96.0.10 kube-dns.kube-system.svc.cluster.local\n\nName:      kubernetes.default.svc.cluster.local\nAddress 1: 10.96.0.1 kubernetes.default.svc.cluster.local\n", true},
+		{"bind", "Server:\t\t10.96.0.10\nAddress:\t10.96.0.10#53\n\nName:\tkubernetes.default.svc.cluster.local\nAddress: 10.96.0.1\n", true},
+		{"nxdomain", "Server:\t\t10.96.0.10\nAddress:\t10.96.0.10#53\n\n** server can't find kubernetes.default.svc.cluster.local: NXDOMAIN\n", false},
+		{"empty", "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := nslookupResolved(tc.out, dnsCheckName); got != tc.want {
+				t.Errorf("nslookupResolved() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDNSPodFinished(t *testing.T) {
+	waiting := func(reason string) core.PodStatus {
+		return core.PodStatus{Phase: core.PodPending, ContainerStatuses: []core.ContainerStatus{{State: core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: reason}}}}}
+	}
+	var tests = []struct {
+		name            string
+		status          core.PodStatus
+		wantDone        bool
+		wantUnavailable bool
+	}{
+		{"succeeded", core.PodStatus{Phase: core.PodSucceeded}, true, false},
+		{"failed", core.PodStatus{Phase: core.PodFailed}, true, false},
+		{"creating", waiting("ContainerCreating"), false, false},
+		{"image pull backoff", waiting("ImagePullBackOff"), true, true},
+		{"image pull error", waiting("ErrImagePull"), true, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			done, err := dnsPodFinished(core.Pod{Status: tc.status})
+			if done != tc.wantDone || errors.Is(err, ErrDNSCheckUnavailable) != tc.wantUnavailable {
+				t.Errorf("dnsPodFinished() = %v, %v, want %v, unavailable %v", done, err, tc.wantDone, tc.wantUnavailable)
+			}
+		})
+	}
+}
+
+func TestWaitForDNSFunctionalUnavailable(t *testing.T) {
+	_, restore := useFakeClock()
+	defer restore()
+
+	cs := fake.NewSimpleClientset()
+	cs.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		// the fake clientset does not generate names
+		pod := action.(clienttesting.CreateAction).GetObject().(*core.Pod)
+		pod.Name = pod.GenerateName + "x7k2p"
+		return false, nil, nil
+	})
+	cs.PrependReactor("get", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		waiting := core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: "ImagePullBackOff"}}
+		return true, &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: action.(clienttesting.GetAction).GetName(), Namespace: action.GetNamespace()},
+			Status:     core.PodStatus{Phase: core.PodPending, ContainerStatuses: []core.ContainerStatus{{Name: "nslookup", State: waiting}}},
+		}, nil
+	})
+
+	err := WaitForDNSFunctional(context.Background(), cs, time.Minute)
+	if !errors.Is(err, ErrDNSCheckUnavailable) || !checkSkipped(err) {
+		t.Fatalf("WaitForDNSFunctional() = %v, want a skipped check wrapping ErrDNSCheckUnavailable", err)
+	}
+
+	created, cleaned := false, false
+	for _, a := range cs.Actions() {
+		if a.GetNamespace() != dnsCheckNamespace {
+			t.Errorf("%s %s in namespace %q, want %q", a.GetVerb(), a.GetResource().Resource, a.GetNamespace(), dnsCheckNamespace)
+		}
+		switch a.GetVerb() {
+		case "create":
+			created = true
+		case "delete-collection":
+			cleaned = a.(clienttesting.DeleteCollectionAction).GetListRestrictions().Labels.String() == dnsCheckLabel+"=true"
+		}
+	}
+	if !created || !cleaned {
+		t.Errorf("WaitForDNSFunctional() created = %v, cleaned up = %v, want the check pod created and deleted", created, cleaned)
+	}
+}
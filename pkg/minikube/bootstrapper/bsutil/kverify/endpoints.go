@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// endpointSliceGroupVersion is the API serving EndpointSlices
+const endpointSliceGroupVersion = "discovery.k8s.io/v1beta1"
+
+// WaitForServiceEndpoints waits for a service to have at least minAddresses ready endpoint addresses.
+// EndpointSlices are preferred where the cluster serves them, as the Endpoints object may then only be a mirror.
+func WaitForServiceEndpoints(ctx context.Context, cs kubernetes.Interface, ns string, name string, minAddresses int, timeout time.Duration) error {
+	glog.Infof("waiting for service %s/%s to have %d endpoints ...", ns, name, minAddresses)
+	start := clk.Now()
+
+	slices := endpointSlicesAvailable(cs)
+	last := "endpoints were never found"
+	populated := func() (bool, error) {
+		n, err := serviceReadyAddresses(cs, ns, name, slices)
+		if err != nil {
+			glog.Infof("temporary error getting endpoints %s/%s: %v", ns, name, err)
+			last = err.Error()
+			return false, nil
+		}
+		last = fmt.Sprintf("%d/%d ready addresses", n, minAddresses)
+		return n >= minAddresses, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, populated); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "service %s/%s endpoints", ns, name)
+		}
+		return fmt.Errorf("service %s/%s never had enough endpoints: %s", ns, name, last)
+	}
+	glog.Infof("duration metric: took %s for service %s/%s to have endpoints ...", since(start), ns, name)
+	return nil
+}
+
+// endpointSlicesAvailable returns whether the cluster serves EndpointSlices
+func endpointSlicesAvailable(cs kubernetes.Interface) bool {
+	if _, err := cs.Discovery().ServerResourcesForGroupVersion(endpointSliceGroupVersion); err != nil {
+		glog.Infof("%s is not available, using Endpoints: %v", endpointSliceGroupVersion, err)
+		return false
+	}
+	return true
+}
+
+// serviceReadyAddresses returns the number of ready addresses of a service, counted from its EndpointSlices if slices is set, or else from its Endpoints
+func serviceReadyAddresses(cs kubernetes.Interface, ns string, name string, slices bool) (int, error) {
+	if slices {
+		eps, err := cs.DiscoveryV1beta1().EndpointSlices(ns).List(meta.ListOptions{LabelSelector: discovery.LabelServiceName + "=" + name})
+		if err != nil {
+			return 0, err
+		}
+		return readySliceAddresses(eps.Items), nil
+	}
+	ep, err := cs.CoreV1().Endpoints(ns).Get(name, meta.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return readyAddresses(ep), nil
+}
+
+// readySliceAddresses returns the number of addresses across all ready endpoints of the slices. An endpoint with an unknown readiness is ready.
+func readySliceAddresses(slices []discovery.EndpointSlice) int {
+	n := 0
+	for _, s := range slices {
+		for _, e := range s.Endpoints {
+			if e.Conditions.Ready != nil && !*e.Conditions.Ready {
+				continue
+			}
+			n += len(e.Addresses)
+		}
+	}
+	return n
+}
+
+// readyAddresses returns the number of ready addresses across all subsets of ep
+func readyAddresses(ep *core.Endpoints) int {
+	n := 0
+	for _, s := range ep.Subsets {
+		n += len(s.Addresses)
+	}
+	return n
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
+)
+
+func TestReadyAddresses(t *testing.T) {
+	ep := &core.Endpoints{Subsets: []core.EndpointSubset{
+		{Addresses: []core.EndpointAddress{{IP: "192.168.39.10"}}, NotReadyAddresses: []core.EndpointAddress{{IP: "192.168.39.11"}}},
+		{Addresses: []core.EndpointAddress{{IP: "192.168.39.12"}}},
+	}}
+	if got := readyAddresses(ep); got != 2 {
+		t.Errorf("readyAddresses() = %d, want 2", got)
+	}
+	if got := readyAddresses(&core.Endpoints{}); got != 0 {
+		t.Errorf("readyAddresses(empty) = %d, want 0", got)
+	}
+}
+
+func TestReadySliceAddresses(t *testing.T) {
+	ready, notReady := true, false
+	slices := []discovery.EndpointSlice{
+		{Endpoints: []discovery.Endpoint{
+			{Addresses: []string{"10.244.0.2"}, Conditions: discovery.EndpointConditions{Ready: &ready}},
+			{Addresses: []string{"10.244.0.3"}, Conditions: discovery.EndpointConditions{Ready: &notReady}},
+		}},
+		{Endpoints: []discovery.Endpoint{{Addresses: []string{"10.244.1.2"}}}},
+	}
+	if got := readySliceAddresses(slices); got != 2 {
+		t.Errorf("readySliceAddresses() = %d, want 2", got)
+	}
+	if got := readySliceAddresses(nil); got != 0 {
+		t.Errorf("readySliceAddresses(nil) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// etcdCertsDir is where kubeadm stores the etcd certificates within the guest
+var etcdCertsDir = path.Join(vmpath.GuestKubernetesCertsDir, "etcd")
+
+// WaitForEtcdHealthy waits for etcd to report itself as healthy via 'etcdctl endpoint health'
+func WaitForEtcdHealthy(ctx context.Context, r cruntime.Manager, cr command.Runner, timeout time.Duration) error {
+	glog.Infof("waiting for etcd to be healthy ...")
+	start := clk.Now()
+
+	var last error
+	healthy := func() (bool, error) {
+		out, err := etcdctl(r, cr, "endpoint", "health")
+		if err != nil {
+			last = err
+			glog.Infof("etcd not healthy yet: %v", err)
+			return false, nil
+		}
+		if !strings.Contains(out, "is healthy") {
+			last = fmt.Errorf("etcd is unhealthy: %s", out)
+			glog.Infof("%v", last)
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, healthy); err != nil {
+		if last != nil {
+			observe(ctx, last.Error())
+		}
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "etcd health")
+		}
+		return errors.Wrap(last, "etcd never became healthy")
+	}
+	glog.Infof("duration metric: took %s for etcd to be healthy ...", since(start))
+	return nil
+}
+
+// etcdEndpointStatus is one member's entry in `etcdctl endpoint status -w json` output
+type etcdEndpointStatus struct {
+	Endpoint string `json:"Endpoint"`
+	Status   struct {
+		Header struct {
+			MemberID uint64 `json:"member_id"`
+		} `json:"header"`
+		Leader   uint64 `json:"leader"`
+		RaftTerm uint64 `json:"raftTerm"`
+	} `json:"Status"`
+}
+
+// VerifyEtcdQuorum returns an error unless a majority of memberCount etcd members agree on the raft term and a single leader
+func VerifyEtcdQuorum(r cruntime.Manager, cr command.Runner, memberCount int) error {
+	out, err := etcdctl(r, cr, "endpoint", "status", "--cluster", "-w", "json")
+	statuses, perr := parseEtcdEndpointStatus(out)
+	if perr != nil {
+		if err != nil {
+			return errors.Wrap(err, "etcd endpoint status")
+		}
+		return perr
+	}
+	if err != nil {
+		// some members did not respond, which quorum may tolerate
+		glog.Infof("etcd endpoint status: %v", err)
+	}
+	return etcdQuorum(statuses, memberCount)
+}
+
+// parseEtcdEndpointStatus parses `etcdctl endpoint status -w json` output, ignoring any warnings around it
+func parseEtcdEndpointStatus(out string) ([]etcdEndpointStatus, error) {
+	start := strings.Index(out, "[")
+	end := strings.LastIndex(out, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no endpoint status found in etcdctl output: %q", out)
+	}
+	var statuses []etcdEndpointStatus
+	if err := json.Unmarshal([]byte(out[start:end+1]), &statuses); err != nil {
+		return nil, errors.Wrap(err, "unmarshal endpoint status")
+	}
+	return statuses, nil
+}
+
+// etcdQuorum returns an error unless a majority of memberCount members report the same raft term and the same leader, which is one of them
+func etcdQuorum(statuses []etcdEndpointStatus, memberCount int) error {
+	majority := memberCount/2 + 1
+
+	// group the responding members by the term and leader they report
+	type view struct {
+		term   uint64
+		leader uint64
+	}
+	views := map[view][]etcdEndpointStatus{}
+	members := map[uint64]bool{}
+	for _, s := range statuses {
+		members[s.Status.Header.MemberID] = true
+		v := view{term: s.Status.RaftTerm, leader: s.Status.Leader}
+		views[v] = append(views[v], s)
+	}
+
+	for v, agree := range views {
+		if len(agree) < majority {
+			continue
+		}
+		if v.leader == 0 || !members[v.leader] {
+			return fmt.Errorf("%d of %d etcd members agree on term %d, but leader %x is not among them", len(agree), memberCount, v.term, v.leader)
+		}
+		glog.Infof("%d of %d etcd members agree on term %d with leader %x", len(agree), memberCount, v.term, v.leader)
+		return nil
+	}
+	return fmt.Errorf("etcd has no quorum: no %d of %d members agree on a raft term and leader (%d responded)", majority, memberCount, len(statuses))
+}
+
+// etcdctl runs etcdctl within the running etcd container, authenticating with the healthcheck client certificate.
+// Output is returned even on error, as etcdctl exits non-zero if any endpoint fails.
+func etcdctl(r cruntime.Manager, cr command.Runner, args ...string) (string, error) {
+	id, err := runningContainerID(r, "etcd")
+	if err != nil {
+		return "", err
+	}
+
+	etcdctl := []string{
+		"ETCDCTL_API=3", "etcdctl",
+		"--endpoints=https://127.0.0.1:2379",
+		fmt.Sprintf("--cacert=%s", path.Join(etcdCertsDir, "ca.crt")),
+		fmt.Sprintf("--cert=%s", path.Join(etcdCertsDir, "healthcheck-client.crt")),
+		fmt.Sprintf("--key=%s", path.Join(etcdCertsDir, "healthcheck-client.key")),
+	}
+	etcdctl = append(etcdctl, args...)
+
+	rr, err := cr.RunCmd(exec.Command("sudo", "crictl", "exec", id, "/bin/sh", "-c", strings.Join(etcdctl, " ")))
+	out := strings.TrimSpace(rr.Stdout.String() + rr.Stderr.String())
+	if err != nil {
+		return out, errors.Wrapf(err, "etcdctl %s: %s", strings.Join(args, " "), rr.Output())
+	}
+	return out, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEtcdQuorum(t *testing.T) {
+	member := func(id uint64, term uint64, leader uint64) string {
+		return fmt.Sprintf(`{"Endpoint":"https://192.168.39.%d:2379","Status":{"header":{"cluster_id":1,"member_id":%d,"revision":9,"raft_term":%d},"version":"3.4.3","dbSize":20480,"leader":%d,"raftIndex":12,"raftTerm":%d}}`, id, id, term, leader, term)
+	}
+	var tests = []struct {
+		name    string
+		out     string
+		members int
+		wantErr bool
+	}{
+		{"healthy", "[" + member(1, 2, 1) + "," + member(2, 2, 1) + "," + member(3, 2, 1) + "]", 3, false},
+		{"one member down", "[" + member(1, 2, 1) + "," + member(2, 2, 1) + "]", 3, false},
+		{"split", "[" + member(1, 2, 1) + "," + member(2, 3, 2) + "," + member(3, 4, 3) + "]", 3, true},
+		{"no leader", "[" + member(1, 2, 0) + "," + member(2, 2, 0) + "," + member(3, 2, 0) + "]", 3, true},
+		{"minority", "[" + member(1, 2, 1) + "]", 3, true},
+		{"single", "{\"level\":\"warn\"}\n[" + member(1, 2, 1) + "]", 1, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			statuses, err := parseEtcdEndpointStatus(tc.out)
+			if err != nil {
+				t.Fatalf("parseEtcdEndpointStatus() error = %v", err)
+			}
+			err = etcdQuorum(statuses, tc.members)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("etcdQuorum() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+)
+
+// eventBuffer is how many events a watcher may fall behind by before events are dropped, so that a slow watcher never slows verification down
+const eventBuffer = 64
+
+// VerificationEvent is a change in the state of a component being verified
+type VerificationEvent struct {
+	// Component is the key of the component, such as "apiserver", or "kubelet"
+	Component string
+	// Old is the previous state of the component, or state.None if it has not been verified before
+	Old state.State
+	// New is the state the component moved to
+	New state.State
+	// Time is when the change happened
+	Time time.Time
+	// Err is why verification failed, if New is state.Error or state.Timeout
+	Err error
+}
+
+// verificationWatcher is a channel receiving the state changes of the verification runs made with a context
+type verificationWatcher struct {
+	sync.Mutex
+	ch     chan VerificationEvent
+	closed bool
+}
+
+// send passes ev to the watcher, unless it has been closed or has fallen too far behind
+func (w *verificationWatcher) send(ev VerificationEvent) {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.ch <- ev:
+	default:
+		glog.Warningf("verification watcher is not keeping up, dropped event: %s %s -> %s", ev.Component, ev.Old, ev.New)
+	}
+}
+
+// watchersKey is the context key for the watchers of verification runs
+type watchersKey struct{}
+
+// WithVerificationWatcher returns a copy of ctx which makes WaitForCluster and WaitForComponents send the component state changes
+// of their run, such as "apiserver: Starting to Running", to the returned channel.
+// The channel is closed once ctx is done. Events are dropped if the receiver falls too far behind.
+func WithVerificationWatcher(ctx context.Context) (context.Context, <-chan VerificationEvent) {
+	w := &verificationWatcher{ch: make(chan VerificationEvent, eventBuffer)}
+	parent := verificationWatchers(ctx)
+	watchers := make([]*verificationWatcher, len(parent), len(parent)+1)
+	copy(watchers, parent)
+	watchers = append(watchers, w)
+
+	go func() {
+		<-ctx.Done()
+		w.Lock()
+		w.closed = true
+		close(w.ch)
+		w.Unlock()
+	}()
+	return context.WithValue(ctx, watchersKey{}, watchers), w.ch
+}
+
+// verificationWatchers returns the watchers carried by ctx
+func verificationWatchers(ctx context.Context) []*verificationWatcher {
+	w, _ := ctx.Value(watchersKey{}).([]*verificationWatcher)
+	return w
+}
+
+// eventStreamKey is the context key for the eventStream of a verification run
+type eventStreamKey struct{}
+
+// eventStream tracks the state of each component in one verification run, so the first event of a run is from state.None
+type eventStream struct {
+	sync.Mutex
+	states   map[string]state.State
+	watchers []*verificationWatcher
+}
+
+// withEventStream returns a copy of ctx which starts a new verification run, whose state changes are sent to the watchers carried by ctx
+func withEventStream(ctx context.Context) context.Context {
+	return context.WithValue(ctx, eventStreamKey{}, &eventStream{states: map[string]state.State{}, watchers: verificationWatchers(ctx)})
+}
+
+// publishState records that a component of the run carried by ctx moved to st, and sends the change to every watcher of the run
+func publishState(ctx context.Context, component string, st state.State, err error) {
+	s, _ := ctx.Value(eventStreamKey{}).(*eventStream)
+	if s == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	old := s.states[component]
+	if old == st && err == nil {
+		return
+	}
+	s.states[component] = st
+	ev := VerificationEvent{Component: component, Old: old, New: st, Time: clk.Now(), Err: err}
+	for _, w := range s.watchers {
+		w.send(ev)
+	}
+}
+
+// publishResult publishes the state a component moved to once its wait returned err
+func publishResult(ctx context.Context, component string, err error, timedOut bool) {
+	switch {
+	case err == nil:
+		publishState(ctx, component, state.Running, nil)
+	case timedOut:
+		publishState(ctx, component, state.Timeout, err)
+	default:
+		publishState(ctx, component, state.Error, err)
+	}
+}
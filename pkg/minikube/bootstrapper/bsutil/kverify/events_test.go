@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/machine/libmachine/state"
+)
+
+func TestWatchVerification(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, events := WithVerificationWatcher(ctx)
+
+	run := withEventStream(ctx)
+	publishState(run, "watched", state.Starting, nil)
+	publishState(run, "watched", state.Starting, nil)
+	publishResult(run, "watched", nil, false)
+	// a new run starts over from state.None
+	next := withEventStream(ctx)
+	publishState(next, "watched", state.Starting, nil)
+	// runs without the watcher are not sent to it
+	publishState(withEventStream(context.Background()), "unwatched", state.Starting, nil)
+
+	want := []struct{ old, new state.State }{{state.None, state.Starting}, {state.Starting, state.Running}, {state.None, state.Starting}}
+	for _, w := range want {
+		ev := <-events
+		if ev.Component != "watched" || ev.Old != w.old || ev.New != w.new {
+			t.Errorf("event = %s %s -> %s, want watched %s -> %s", ev.Component, ev.Old, ev.New, w.old, w.new)
+		}
+	}
+
+	cancel()
+	if ev, ok := <-events; ok {
+		t.Errorf("channel still open after ctx was cancelled, got %s %s -> %s", ev.Component, ev.Old, ev.New)
+	}
+	// publishing after the watcher closed must not panic
+	publishState(run, "watched", state.Error, nil)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// featureEnabledMetric is the apiserver metric reporting the state of each feature gate, on versions which export it
+const featureEnabledMetric = "kubernetes_feature_enabled"
+
+// VerifyFeatureGate returns an error unless the apiserver has the feature gate in the expected state.
+// The effective state is read from the apiserver metrics where available, and otherwise from the --feature-gates flag of the apiserver pod.
+func VerifyFeatureGate(cs kubernetes.Interface, gate string, expected bool) error {
+	enabled, found, err := apiServerFeatureGate(cs, gate)
+	if err != nil {
+		return errors.Wrapf(err, "feature gate %q", gate)
+	}
+	if !found {
+		return fmt.Errorf("feature gate %q is not set on the apiserver, want %s=%t: check its spelling", gate, gate, expected)
+	}
+	if enabled != expected {
+		return fmt.Errorf("feature gate %q is %t on the apiserver, want %t", gate, enabled, expected)
+	}
+	glog.Infof("apiserver feature gate %s=%t", gate, enabled)
+	return nil
+}
+
+// apiServerFeatureGate returns the state of a feature gate on the apiserver, and whether it was found
+func apiServerFeatureGate(cs kubernetes.Interface, gate string) (bool, bool, error) {
+	raw, err := cs.Discovery().RESTClient().Get().AbsPath("/metrics").DoRaw()
+	if err != nil {
+		glog.Infof("unable to read apiserver metrics, falling back to its flags: %v", err)
+	} else if enabled, found := metricFeatureGate(string(raw), gate); found {
+		return enabled, true, nil
+	}
+
+	pods, err := cs.CoreV1().Pods("kube-system").List(meta.ListOptions{LabelSelector: "component=kube-apiserver"})
+	if err != nil {
+		return false, false, errors.Wrap(err, "list apiserver pods")
+	}
+	if len(pods.Items) == 0 {
+		return false, false, fmt.Errorf("no apiserver pod found")
+	}
+	gates, err := podFeatureGates(pods.Items[0])
+	if err != nil {
+		return false, false, err
+	}
+	enabled, found := gates[gate]
+	return enabled, found, nil
+}
+
+// metricFeatureGate returns the state of a feature gate from apiserver metrics, and whether it was reported
+func metricFeatureGate(metrics string, gate string) (bool, bool) {
+	label := fmt.Sprintf("name=%q", gate)
+	s := bufio.NewScanner(strings.NewReader(metrics))
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if !strings.HasPrefix(line, featureEnabledMetric+"{") {
+			continue
+		}
+		end := strings.Index(line, "}")
+		if end < 0 || !strings.Contains(line[:end], label) {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(line[end+1:]), 64)
+		if err != nil {
+			continue
+		}
+		return v == 1, true
+	}
+	return false, false
+}
+
+// podFeatureGates returns the feature gates passed to the containers of a pod through --feature-gates
+func podFeatureGates(pod core.Pod) (map[string]bool, error) {
+	gates := map[string]bool{}
+	for _, c := range pod.Spec.Containers {
+		for _, arg := range append(c.Command, c.Args...) {
+			if !strings.HasPrefix(arg, "--feature-gates=") {
+				continue
+			}
+			for _, kv := range strings.Split(strings.TrimPrefix(arg, "--feature-gates="), ",") {
+				fg := strings.SplitN(kv, "=", 2)
+				if len(fg) != 2 {
+					continue
+				}
+				v, err := strconv.ParseBool(strings.TrimSpace(fg[1]))
+				if err != nil {
+					return nil, errors.Wrapf(err, "feature gate %q in pod %s", fg[0], pod.Name)
+				}
+				gates[strings.TrimSpace(fg[0])] = v
+			}
+		}
+	}
+	return gates, nil
+}
+
+// verifyFeatureGates verifies that the apiserver accepted each component feature gate requested in cfg
+func verifyFeatureGates(cs kubernetes.Interface, cfg config.ClusterConfig) error {
+	gates, err := bsutil.ComponentFeatureGates(cfg.KubernetesConfig.FeatureGates)
+	if err != nil {
+		return errors.Wrap(err, "parse feature gates")
+	}
+	names := []string{}
+	for name := range gates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := VerifyFeatureGate(cs, name, gates[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+)
+
+func TestMetricFeatureGate(t *testing.T) {
+	metrics := `# HELP kubernetes_feature_enabled [ALPHA] This metric records the data about the stage and enablement of a k8s feature.
+# TYPE kubernetes_feature_enabled gauge
+kubernetes_feature_enabled{name="EphemeralContainers",stage="BETA"} 1
+kubernetes_feature_enabled{name="InPlacePodVerticalScaling",stage="ALPHA"} 0
+`
+	var tests = []struct {
+		gate        string
+		wantEnabled bool
+		wantFound   bool
+	}{
+		{"EphemeralContainers", true, true},
+		{"InPlacePodVerticalScaling", false, true},
+		{"EphemeralContainer", false, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.gate, func(t *testing.T) {
+			enabled, found := metricFeatureGate(metrics, tc.gate)
+			if enabled != tc.wantEnabled || found != tc.wantFound {
+				t.Errorf("metricFeatureGate() = %v, %v, want %v, %v", enabled, found, tc.wantEnabled, tc.wantFound)
+			}
+		})
+	}
+}
+
+func TestPodFeatureGates(t *testing.T) {
+	pod := core.Pod{Spec: core.PodSpec{Containers: []core.Container{{Command: []string{"kube-apiserver", "--secure-port=8443", "--feature-gates=EphemeralContainers=true,CSIMigration=false"}}}}}
+	got, err := podFeatureGates(pod)
+	if err != nil {
+		t.Fatalf("podFeatureGates() error = %v", err)
+	}
+	if len(got) != 2 || !got["EphemeralContainers"] || got["CSIMigration"] {
+		t.Errorf("podFeatureGates() = %v, want EphemeralContainers=true, CSIMigration=false", got)
+	}
+}
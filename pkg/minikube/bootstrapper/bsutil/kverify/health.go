@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// ErrUnhealthy is returned with state.Error by HealthCheck when a component is running, but failing its health check
+var ErrUnhealthy = errors.New("running, but failing its health check")
+
+// HealthCheck runs one-shot checks of the cluster components that start waits for, so that status reports the same health start does
+type HealthCheck struct {
+	// Runner runs commands on the node being checked
+	Runner command.Runner
+	// Config is the cluster configuration
+	Config config.ClusterConfig
+	// Hostname is the address the apiserver is reached at
+	Hostname string
+	// Port is the port the apiserver is reached at
+	Port int
+	// Clients provides the apiserver client. If nil, one is built from Config, Hostname and Port.
+	Clients *ClientProvider
+}
+
+// APIServer returns the state of the apiserver process and its healthz endpoint
+func (h *HealthCheck) APIServer() (state.State, error) {
+	return APIServerStatus(h.Runner, h.Hostname, h.Port)
+}
+
+// Kubelet returns the state of the kubelet service, or state.Error and ErrUnhealthy if it is running but its healthz endpoint is failing
+func (h *HealthCheck) Kubelet() (state.State, error) {
+	kh, err := CheckKubeletHealth(context.Background(), h.Runner)
+	if err != nil {
+		return state.Error, err
+	}
+	if kh.SystemdState == state.Running && !kh.Healthy() {
+		return state.Error, ErrUnhealthy
+	}
+	return kh.SystemdState, nil
+}
+
+// SystemPods returns whether the expected kube-system components are running
+func (h *HealthCheck) SystemPods() (state.State, error) {
+	client, err := h.clients().Client()
+	if err != nil {
+		return state.Error, errors.Wrap(err, "client")
+	}
+	if err := ExpectedComponentsRunningFor(context.Background(), client, h.Config); err != nil {
+		glog.Infof("system pods: %v", err)
+		return state.Error, err
+	}
+	return state.Running, nil
+}
+
+// DNS returns whether cluster DNS resolves the kubernetes service from within a pod, or state.None if the check pod is unable to run
+func (h *HealthCheck) DNS() (state.State, error) {
+	client, err := h.clients().Client()
+	if err != nil {
+		return state.Error, errors.Wrap(err, "client")
+	}
+	if err := checkDNS(context.Background(), client); err != nil {
+		glog.Infof("dns: %v", err)
+		if errors.Is(err, ErrDNSCheckUnavailable) {
+			return state.None, err
+		}
+		return state.Error, err
+	}
+	return state.Running, nil
+}
+
+// ComponentHealth is the outcome of one HealthCheck, in the form used by the status JSON output
+type ComponentHealth struct {
+	// State is the state of the component, such as Running or Error
+	State string `json:"state"`
+	// Error describes why the component is not healthy, if it is not
+	Error string `json:"error,omitempty"`
+}
+
+// HealthReport is the outcome of every HealthCheck, in the form used by the status JSON output
+type HealthReport struct {
+	APIServer  ComponentHealth `json:"apiserver"`
+	Kubelet    ComponentHealth `json:"kubelet"`
+	SystemPods ComponentHealth `json:"system_pods"`
+	DNS        ComponentHealth `json:"dns"`
+}
+
+// Report runs every check. The checks which need the apiserver are reported as Unknown unless it is running.
+func (h *HealthCheck) Report() *HealthReport {
+	r := &HealthReport{
+		APIServer: healthOf(h.APIServer()),
+		Kubelet:   healthOf(h.Kubelet()),
+	}
+	if r.APIServer.State != state.Running.String() {
+		unknown := ComponentHealth{State: "Unknown", Error: "apiserver is not running"}
+		r.SystemPods = unknown
+		r.DNS = unknown
+		return r
+	}
+	r.SystemPods = healthOf(h.SystemPods())
+	r.DNS = healthOf(h.DNS())
+	return r
+}
+
+// healthOf returns the ComponentHealth for the result of a check. state.None is reported as Unknown.
+func healthOf(st state.State, err error) ComponentHealth {
+	ch := ComponentHealth{State: st.String()}
+	if st == state.None {
+		ch.State = "Unknown"
+	}
+	if err != nil {
+		ch.Error = err.Error()
+	}
+	return ch
+}
+
+// clients returns the ClientProvider, building one on first use
+func (h *HealthCheck) clients() *ClientProvider {
+	if h.Clients == nil {
+		h.Clients = NewClientProvider(h.Config, h.Hostname, h.Port)
+	}
+	return h.Clients
+}
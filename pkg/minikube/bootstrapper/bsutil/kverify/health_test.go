@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestHealthCheckKubelet(t *testing.T) {
+	var tests = []struct {
+		name    string
+		active  string
+		healthz string
+		want    state.State
+		wantErr error
+	}{
+		{"healthy", "active", "ok", state.Running, nil},
+		{"unhealthy", "active", "[-]syncloop failed", state.Error, ErrUnhealthy},
+		{"stopped", "inactive", "", state.Stopped, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(map[string]string{
+				"sudo systemctl is-active kubelet":                     tc.active,
+				"curl -sS --max-time 5 http://localhost:10248/healthz": tc.healthz,
+			})
+			hc := &HealthCheck{Runner: cr}
+			got, err := hc.Kubelet()
+			if got != tc.want || err != tc.wantErr {
+				t.Errorf("Kubelet() = %s, %v, want %s, %v", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestHealthOf(t *testing.T) {
+	var tests = []struct {
+		name string
+		st   state.State
+		err  error
+		want ComponentHealth
+	}{
+		{"running", state.Running, nil, ComponentHealth{State: "Running"}},
+		{"unhealthy", state.Error, ErrUnhealthy, ComponentHealth{State: "Error", Error: "running, but failing its health check"}},
+		{"unknown", state.None, ErrDNSCheckUnavailable, ComponentHealth{State: "Unknown", Error: "dns check pod is unable to run"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := healthOf(tc.st, tc.err); got != tc.want {
+				t.Errorf("healthOf() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
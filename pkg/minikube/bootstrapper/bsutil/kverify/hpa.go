@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	autoscaling "k8s.io/api/autoscaling/v2beta1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForHPAMetrics waits for a HorizontalPodAutoscaler to have computed its current metrics, rather than reporting them as unknown.
+// On timeout, the error includes the HPA conditions, which say why metrics could not be fetched.
+func WaitForHPAMetrics(ctx context.Context, cs kubernetes.Interface, ns string, name string, timeout time.Duration) error {
+	glog.Infof("waiting for hpa %s/%s to have current metrics ...", ns, name)
+	start := clk.Now()
+
+	last := "hpa was never found"
+	computed := func() (bool, error) {
+		hpa, err := cs.AutoscalingV2beta1().HorizontalPodAutoscalers(ns).Get(name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting hpa %s/%s: %v", ns, name, err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := hpaMetricsReady(hpa)
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, computed); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "hpa %s/%s", ns, name)
+		}
+		return fmt.Errorf("hpa %s/%s never had current metrics: %s", ns, name, last)
+	}
+	glog.Infof("duration metric: took %s for hpa %s/%s to have current metrics ...", since(start), ns, name)
+	return nil
+}
+
+// hpaMetricsReady returns whether an HPA has current metrics and is able to scale, and a description of its conditions
+func hpaMetricsReady(hpa *autoscaling.HorizontalPodAutoscaler) (bool, string) {
+	conds := []string{}
+	active := true
+	for _, c := range hpa.Status.Conditions {
+		conds = append(conds, fmt.Sprintf("%s=%s (%s: %s)", c.Type, c.Status, c.Reason, c.Message))
+		if c.Type == autoscaling.ScalingActive && c.Status == core.ConditionFalse {
+			active = false
+		}
+	}
+	msg := fmt.Sprintf("%d/%d metrics current", len(hpa.Status.CurrentMetrics), len(hpa.Spec.Metrics))
+	if len(conds) > 0 {
+		msg = fmt.Sprintf("%s, conditions: %s", msg, strings.Join(conds, "; "))
+	}
+	return active && len(hpa.Status.CurrentMetrics) > 0, msg
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	autoscaling "k8s.io/api/autoscaling/v2beta1"
+	core "k8s.io/api/core/v1"
+)
+
+func TestHPAMetricsReady(t *testing.T) {
+	current := []autoscaling.MetricStatus{{Type: autoscaling.ResourceMetricSourceType, Resource: &autoscaling.ResourceMetricStatus{Name: core.ResourceCPU}}}
+	failing := []autoscaling.HorizontalPodAutoscalerCondition{{Type: autoscaling.ScalingActive, Status: core.ConditionFalse, Reason: "FailedGetResourceMetric", Message: "unable to fetch metrics from resource metrics API"}}
+	active := []autoscaling.HorizontalPodAutoscalerCondition{{Type: autoscaling.ScalingActive, Status: core.ConditionTrue, Reason: "ValidMetricFound"}}
+	var tests = []struct {
+		name   string
+		status autoscaling.HorizontalPodAutoscalerStatus
+		want   bool
+	}{
+		{"computed", autoscaling.HorizontalPodAutoscalerStatus{CurrentMetrics: current, Conditions: active}, true},
+		{"unknown", autoscaling.HorizontalPodAutoscalerStatus{}, false},
+		{"unable to fetch", autoscaling.HorizontalPodAutoscalerStatus{Conditions: failing}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, msg := hpaMetricsReady(&autoscaling.HorizontalPodAutoscaler{Status: tc.status}); got != tc.want {
+				t.Errorf("hpaMetricsReady() = %v (%s), want %v", got, msg, tc.want)
+			}
+		})
+	}
+}
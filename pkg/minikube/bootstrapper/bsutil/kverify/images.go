@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/minikube/pkg/util"
+)
+
+// versionedComponents are the control plane components whose image tag is the kubernetes version
+var versionedComponents = map[string]bool{
+	"kube-apiserver":          true,
+	"kube-controller-manager": true,
+	"kube-scheduler":          true,
+}
+
+// VerifyComponentImages returns an error if any control plane pod runs an image not tagged with the requested kubernetes version
+func VerifyComponentImages(cs kubernetes.Interface, version string) error {
+	v, err := util.ParseKubernetesVersion(version)
+	if err != nil {
+		return errors.Wrap(err, "parse kubernetes version")
+	}
+	want := "v" + v.String()
+
+	pods, err := cs.CoreV1().Pods("kube-system").List(meta.ListOptions{LabelSelector: "tier=control-plane"})
+	if err != nil {
+		return errors.Wrap(err, "list control plane pods")
+	}
+
+	mismatched := []string{}
+	for _, pod := range pods.Items {
+		component := pod.ObjectMeta.Labels["component"]
+		if !versionedComponents[component] {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			tag := imageTag(c.Image)
+			if tag == "" {
+				glog.Infof("%s image %s has no tag, skipping version check", component, c.Image)
+				continue
+			}
+			if tag != want {
+				mismatched = append(mismatched, fmt.Sprintf("%s is running %s", component, c.Image))
+			}
+		}
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("control plane images do not match kubernetes %s: %s", want, strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+// imageTag returns the tag of an image reference, or "" if it has none
+func imageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	i := strings.LastIndex(image, ":")
+	// a colon before the last slash belongs to a registry port
+	if i < 0 || strings.LastIndex(image, "/") > i {
+		return ""
+	}
+	return image[i+1:]
+}
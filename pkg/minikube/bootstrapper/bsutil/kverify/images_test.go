@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import "testing"
+
+func TestImageTag(t *testing.T) {
+	var tests = []struct {
+		image string
+		want  string
+	}{
+		{"k8s.gcr.io/kube-apiserver:v1.18.0", "v1.18.0"},
+		{"localhost:5000/kube-apiserver:v1.18.0", "v1.18.0"},
+		{"localhost:5000/kube-apiserver", ""},
+		{"k8s.gcr.io/kube-apiserver@sha256:abcd", ""},
+		{"k8s.gcr.io/kube-apiserver:v1.18.0@sha256:abcd", "v1.18.0"},
+		{"kube-apiserver", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.image, func(t *testing.T) {
+			if got := imageTag(tc.image); got != tc.want {
+				t.Errorf("imageTag(%q) = %q, want %q", tc.image, got, tc.want)
+			}
+		})
+	}
+}
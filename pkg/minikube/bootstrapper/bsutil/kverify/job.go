@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// jobLogLines is how many lines of each failed pod's log to include when a Job fails
+const jobLogLines = 20
+
+// WaitForJobComplete waits for a Job to complete, returning the logs of its failed pods if it fails
+func WaitForJobComplete(ctx context.Context, cs kubernetes.Interface, ns string, name string, timeout time.Duration) error {
+	glog.Infof("waiting for job %s/%s to complete ...", ns, name)
+	start := clk.Now()
+
+	last := "job was never found"
+	var failed *batch.Job
+	complete := func() (bool, error) {
+		job, err := cs.BatchV1().Jobs(ns).Get(name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting job %s/%s: %v", ns, name, err)
+			last = err.Error()
+			return false, nil
+		}
+		done, isFailed, msg := jobStatus(job)
+		last = msg
+		if isFailed {
+			failed = job
+			return true, nil
+		}
+		return done, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, complete); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "job %s/%s", ns, name)
+		}
+		return fmt.Errorf("job %s/%s never completed: %s", ns, name, last)
+	}
+	if failed != nil {
+		return fmt.Errorf("job %s/%s failed: %s%s", ns, name, last, failedJobLogs(cs, failed))
+	}
+	glog.Infof("duration metric: took %s for job %s/%s to complete ...", since(start), ns, name)
+	return nil
+}
+
+// jobStatus returns whether a Job has completed or failed, and a description of its status
+func jobStatus(job *batch.Job) (bool, bool, string) {
+	st := job.Status
+	msg := fmt.Sprintf("%d succeeded, %d active, %d failed", st.Succeeded, st.Active, st.Failed)
+	for _, c := range st.Conditions {
+		if c.Status != core.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batch.JobFailed:
+			return false, true, fmt.Sprintf("%s: %s", msg, c.Reason)
+		case batch.JobComplete:
+			return st.Succeeded > 0, false, msg
+		}
+	}
+	return false, false, msg
+}
+
+// failedJobLogs returns the tail of the logs of a Job's failed pods, formatted for an error message
+func failedJobLogs(cs kubernetes.Interface, job *batch.Job) string {
+	selector, err := meta.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		glog.Warningf("job %s/%s selector: %v", job.Namespace, job.Name, err)
+		return ""
+	}
+	pods, err := cs.CoreV1().Pods(job.Namespace).List(meta.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		glog.Warningf("unable to list pods of job %s/%s: %v", job.Namespace, job.Name, err)
+		return ""
+	}
+
+	var sb strings.Builder
+	tail := int64(jobLogLines)
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != core.PodFailed {
+			continue
+		}
+		raw, err := cs.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &core.PodLogOptions{TailLines: &tail}).DoRaw()
+		if err != nil {
+			glog.Warningf("unable to get logs of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		fmt.Fprintf(&sb, "\n==> pod %q <==\n%s", pod.Name, strings.TrimSpace(string(raw)))
+	}
+	return sb.String()
+}
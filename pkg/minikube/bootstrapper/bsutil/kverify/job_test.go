@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+func TestJobStatus(t *testing.T) {
+	cond := func(ct batch.JobConditionType, reason string) []batch.JobCondition {
+		return []batch.JobCondition{{Type: ct, Status: core.ConditionTrue, Reason: reason}}
+	}
+	var tests = []struct {
+		name       string
+		status     batch.JobStatus
+		wantDone   bool
+		wantFailed bool
+	}{
+		{"running", batch.JobStatus{Active: 1}, false, false},
+		{"complete", batch.JobStatus{Succeeded: 1, Conditions: cond(batch.JobComplete, "")}, true, false},
+		{"succeeded without condition", batch.JobStatus{Succeeded: 1}, false, false},
+		{"failed", batch.JobStatus{Failed: 6, Conditions: cond(batch.JobFailed, "BackoffLimitExceeded")}, false, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			done, failed, msg := jobStatus(&batch.Job{Status: tc.status})
+			if done != tc.wantDone || failed != tc.wantFailed {
+				t.Errorf("jobStatus() = %v, %v (%s), want %v, %v", done, failed, msg, tc.wantDone, tc.wantFailed)
+			}
+		})
+	}
+}
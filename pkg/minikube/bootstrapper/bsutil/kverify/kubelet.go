@@ -0,0 +1,240 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// KubeletStatus checks the kubelet status
+func KubeletStatus(ctx context.Context, cr command.Runner) (state.State, error) {
+	st, _, err := kubeletStatus(ctx, cr)
+	return st, err
+}
+
+// kubeletStatus checks the kubelet status, also returning what systemctl output
+func kubeletStatus(ctx context.Context, cr command.Runner) (state.State, string, error) {
+	glog.Infof("Checking kubelet status ...")
+	if err := ctx.Err(); err != nil {
+		return state.None, "", errors.Wrap(err, "kubelet")
+	}
+	rr, err := cr.RunCmd(privilegedCommand(ctx, cr, "systemctl", "is-active", "kubelet"))
+	if err != nil {
+		// Do not return now, as we still have parsing to do!
+		glog.Warningf("%s returned error: %v", rr.Command(), err)
+	}
+	s := strings.TrimSpace(rr.Stdout.String())
+	glog.Infof("kubelet is-active: %s", s)
+	switch s {
+	case "active":
+		return state.Running, s, nil
+	case "inactive":
+		return state.Stopped, s, nil
+	case "activating":
+		return state.Starting, s, nil
+	case "deactivating":
+		return state.Stopping, s, nil
+	case "failed":
+		return state.Error, s, fmt.Errorf("kubelet has failed: systemd reports %q", s)
+	}
+	return state.Error, s, fmt.Errorf("kubelet is in an unknown state: systemctl output %q, stderr %q%s", s, strings.TrimSpace(rr.Stderr.String()), kubeletJournal(ctx, cr))
+}
+
+// kubeletJournalLines is how many lines of the kubelet journal to include when its state is unknown
+const kubeletJournalLines = 25
+
+// kubeletJournal returns the tail of the kubelet journal, formatted to be appended to an error, or "" if it is unavailable
+func kubeletJournal(ctx context.Context, cr command.Runner) string {
+	rr, err := cr.RunCmd(privilegedCommand(ctx, cr, "journalctl", "-u", "kubelet", "-n", fmt.Sprint(kubeletJournalLines), "--no-pager"))
+	if err != nil {
+		glog.Infof("unable to read kubelet journal: %v", err)
+		return ""
+	}
+	return fmt.Sprintf("\nkubelet journal:\n%s", strings.TrimSpace(rr.Stdout.String()))
+}
+
+// WaitForKubeletActive waits for systemd to report the kubelet as active, retrying while it is activating
+func WaitForKubeletActive(ctx context.Context, cr command.Runner, timeout time.Duration) error {
+	glog.Infof("waiting for kubelet to be active ...")
+	start := clk.Now()
+
+	last := ""
+	active := func() (bool, error) {
+		st, out, err := kubeletStatus(ctx, cr)
+		last = out
+		if err != nil {
+			glog.Infof("kubelet status: %v", err)
+		}
+		return st == state.Running, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, active); err != nil {
+		observe(ctx, fmt.Sprintf("%+v", last))
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "kubelet")
+		}
+		return fmt.Errorf("kubelet never became active, last systemctl output: %q", last)
+	}
+	glog.Infof("duration metric: took %s for kubelet to be active ...", since(start))
+	return nil
+}
+
+// kubeletHealthzURL is the kubelet's healthz endpoint, only served on localhost
+const kubeletHealthzURL = "http://localhost:10248/healthz"
+
+// KubeletHealth is the health of the kubelet, as seen by systemd and by its healthz endpoint
+type KubeletHealth struct {
+	// SystemdState is the state of the kubelet service
+	SystemdState state.State
+	// HealthzOK is whether the kubelet healthz endpoint returned ok
+	HealthzOK bool
+	// HealthzUnknown is set if the healthz endpoint could not be checked, for example because curl is missing
+	HealthzUnknown bool
+}
+
+// Healthy returns whether the kubelet process is running and reports itself healthy.
+// If the healthz endpoint could not be checked, the systemd state alone decides.
+func (h KubeletHealth) Healthy() bool {
+	return h.SystemdState == state.Running && (h.HealthzOK || h.HealthzUnknown)
+}
+
+// curl exit codes meaning the endpoint was not served, rather than curl failing to run
+const (
+	curlCouldNotConnect = 7
+	curlTimedOut        = 28
+)
+
+// CheckKubeletHealth checks the kubelet service state, and if it is running, the kubelet healthz endpoint
+func CheckKubeletHealth(ctx context.Context, cr command.Runner) (KubeletHealth, error) {
+	st, err := KubeletStatus(ctx, cr)
+	h := KubeletHealth{SystemdState: st}
+	if err != nil || st != state.Running {
+		return h, err
+	}
+
+	rr, err := cr.RunCmd(exec.CommandContext(ctx, "curl", "-sS", "--max-time", "5", kubeletHealthzURL))
+	if err != nil {
+		glog.Infof("kubelet healthz: %v", err)
+		if rr.ExitCode != curlCouldNotConnect && rr.ExitCode != curlTimedOut {
+			glog.Warningf("unable to check kubelet healthz, relying on systemd state %s", st)
+			h.HealthzUnknown = true
+		}
+		return h, nil
+	}
+	body := strings.TrimSpace(rr.Stdout.String())
+	glog.Infof("kubelet healthz returned: %s", body)
+	h.HealthzOK = body == "ok"
+	return h, nil
+}
+
+// WaitForKubeletHealthy waits for the kubelet to be active and for its healthz endpoint to return ok
+func WaitForKubeletHealthy(ctx context.Context, cr command.Runner, timeout time.Duration) error {
+	if err := WaitForKubeletActive(ctx, cr, timeout); err != nil {
+		return err
+	}
+
+	glog.Infof("waiting for kubelet healthz to return ok ...")
+	start := clk.Now()
+
+	last := KubeletHealth{}
+	healthy := func() (bool, error) {
+		h, err := CheckKubeletHealth(ctx, cr)
+		if err != nil {
+			glog.Infof("kubelet health: %v", err)
+		}
+		last = h
+		return h.Healthy(), nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, healthy); err != nil {
+		observe(ctx, fmt.Sprintf("%+v", last))
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "kubelet healthz")
+		}
+		return fmt.Errorf("kubelet never became healthy, last health: %+v", last)
+	}
+	glog.Infof("duration metric: took %s for kubelet healthz to return ok ...", since(start))
+	return nil
+}
+
+// PreflightKubelet returns human-readable hints for conditions known to stop the kubelet from running with the given container runtime
+func PreflightKubelet(cr command.Runner, runtime string) []string {
+	hints := []string{}
+
+	rr, err := cr.RunCmd(exec.Command("sudo", "swapon", "--show"))
+	if err != nil {
+		glog.Infof("unable to check swap: %v", err)
+	} else if strings.TrimSpace(rr.Stdout.String()) != "" {
+		hints = append(hints, "swap is enabled, which the kubelet refuses to run with: run 'sudo swapoff -a'")
+	}
+
+	if err := VerifyCgroupDriver(cr, runtime); err != nil {
+		hints = append(hints, err.Error())
+	}
+	return hints
+}
+
+// kubeletVersionRe matches the version in `kubelet --version` output, such as "Kubernetes v1.18.0"
+var kubeletVersionRe = regexp.MustCompile(`v\d+\.\d+\.\d+\S*`)
+
+// KubeletVersion returns the version of the running kubelet, such as "v1.18.0"
+func KubeletVersion(cr command.Runner) (string, error) {
+	bin := "kubelet"
+	if pid, err := kubeletPID(cr); err == nil {
+		// Ask the binary that is actually running, as it may not be in $PATH
+		rr, err := cr.RunCmd(exec.Command("sudo", "readlink", "-f", fmt.Sprintf("/proc/%d/exe", pid)))
+		if err == nil {
+			bin = strings.TrimSpace(rr.Stdout.String())
+		}
+	}
+
+	rr, err := cr.RunCmd(exec.Command("sudo", bin, "--version"))
+	if err != nil {
+		return "", errors.Wrap(err, "kubelet --version")
+	}
+	return parseKubeletVersion(rr.Stdout.String())
+}
+
+// parseKubeletVersion parses the output of `kubelet --version`
+func parseKubeletVersion(out string) (string, error) {
+	v := kubeletVersionRe.FindString(out)
+	if v == "" {
+		return "", fmt.Errorf("unable to parse kubelet version from %q", strings.TrimSpace(out))
+	}
+	return v, nil
+}
+
+// kubeletPID returns the pid of the running kubelet
+func kubeletPID(cr command.Runner) (int, error) {
+	rr, err := cr.RunCmd(exec.Command("sudo", "pgrep", "-xn", "kubelet"))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(rr.Stdout.String()))
+}
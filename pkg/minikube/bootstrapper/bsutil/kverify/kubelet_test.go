@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestKubeletStatus(t *testing.T) {
+	var tests = []struct {
+		output  string
+		want    state.State
+		wantErr bool
+	}{
+		{"active", state.Running, false},
+		{"inactive", state.Stopped, false},
+		{"activating", state.Starting, false},
+		{"deactivating", state.Stopping, false},
+		{"failed", state.Error, true},
+		{"unknown", state.Error, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.output, func(t *testing.T) {
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(map[string]string{
+				"sudo systemctl is-active kubelet":            tc.output,
+				"sudo journalctl -u kubelet -n 25 --no-pager": "kubelet.go:1234] something odd",
+			})
+
+			got, err := KubeletStatus(context.Background(), cr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("KubeletStatus() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("KubeletStatus() = %s, want %s", got, tc.want)
+			}
+			if tc.output == "unknown" && !strings.Contains(err.Error(), "something odd") {
+				t.Errorf("KubeletStatus() error = %v, want it to include the kubelet journal", err)
+			}
+		})
+	}
+}
+
+func TestParseKubeletVersion(t *testing.T) {
+	var tests = []struct {
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{"Kubernetes v1.18.0\n", "v1.18.0", false},
+		{"Kubernetes v1.18.0-beta.2", "v1.18.0-beta.2", false},
+		{"command not found", "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.output, func(t *testing.T) {
+			got, err := parseKubeletVersion(tc.output)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseKubeletVersion(%q) error = %v, wantErr %v", tc.output, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseKubeletVersion(%q) = %q, want %q", tc.output, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWaitForKubeletActive(t *testing.T) {
+	_, restore := useFakeClock()
+	defer restore()
+
+	cr := command.NewFakeCommandRunner()
+	cr.SetCommandToOutput(map[string]string{"sudo systemctl is-active kubelet": "activating"})
+	err := WaitForKubeletActive(context.Background(), cr, time.Minute)
+	want := `kubelet never became active, last systemctl output: "activating"`
+	if err == nil || err.Error() != want {
+		t.Errorf("WaitForKubeletActive() error = %v, want %q", err, want)
+	}
+
+	cr.SetCommandToOutput(map[string]string{"sudo systemctl is-active kubelet": "active"})
+	if err := WaitForKubeletActive(context.Background(), cr, time.Minute); err != nil {
+		t.Errorf("WaitForKubeletActive() error = %v, want nil", err)
+	}
+}
+
+func TestCheckKubeletHealth(t *testing.T) {
+	var tests = []struct {
+		name    string
+		active  string
+		healthz string
+		want    KubeletHealth
+		healthy bool
+	}{
+		{"healthy", "active", "ok", KubeletHealth{SystemdState: state.Running, HealthzOK: true}, true},
+		{"unhealthy", "active", "[-]syncloop failed", KubeletHealth{SystemdState: state.Running, HealthzOK: false}, false},
+		{"stopped", "inactive", "", KubeletHealth{SystemdState: state.Stopped, HealthzOK: false}, false},
+		{"curl missing", "active", "", KubeletHealth{SystemdState: state.Running, HealthzUnknown: true}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmds := map[string]string{"sudo systemctl is-active kubelet": tc.active}
+			if tc.healthz != "" {
+				cmds["curl -sS --max-time 5 http://localhost:10248/healthz"] = tc.healthz
+			}
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(cmds)
+			got, err := CheckKubeletHealth(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("CheckKubeletHealth() error = %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("CheckKubeletHealth() = %+v, want %+v", got, tc.want)
+			}
+			if got.Healthy() != tc.healthy {
+				t.Errorf("%+v.Healthy() = %v, want %v", got, got.Healthy(), tc.healthy)
+			}
+		})
+	}
+}
+
+func TestPreflightKubelet(t *testing.T) {
+	var tests = []struct {
+		name    string
+		runtime string
+		swap    string
+		kubelet string
+		files   map[string]string
+		want    int
+	}{
+		{"healthy", "docker", "", "kind: KubeletConfiguration\ncgroupDriver: systemd\n", map[string]string{"docker info --format {{.CgroupDriver}}": "systemd"}, 0},
+		{"swap", "docker", "NAME      TYPE SIZE USED PRIO\n/dev/sda2 partition 2G 0B -2\n", "cgroupDriver: cgroupfs\n", map[string]string{"docker info --format {{.CgroupDriver}}": "cgroupfs"}, 1},
+		{"cgroup mismatch", "docker", "", "cgroupDriver: \"systemd\"\n", map[string]string{"docker info --format {{.CgroupDriver}}": "cgroupfs"}, 1},
+		{"both", "docker", "/swapfile file 1G 0B -2\n", "cgroupDriver: systemd\n", map[string]string{"docker info --format {{.CgroupDriver}}": "cgroupfs"}, 2},
+		{"kubelet default", "docker", "", "kind: KubeletConfiguration\n", map[string]string{"docker info --format {{.CgroupDriver}}": "systemd"}, 1},
+		{"crio match", "crio", "", "cgroupDriver: systemd\n", map[string]string{"sudo cat /etc/crio/crio.conf": "cgroup_manager = \"systemd\"\n"}, 0},
+		{"containerd mismatch", "containerd", "", "cgroupDriver: systemd\n", map[string]string{"sudo cat /etc/containerd/config.toml": "[plugins.cri]\n  systemd_cgroup = false\n"}, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(map[string]string{
+				"sudo swapon --show":                    tc.swap,
+				"sudo cat /var/lib/kubelet/config.yaml": tc.kubelet,
+			})
+			cr.SetCommandToOutput(tc.files)
+			if got := PreflightKubelet(cr, tc.runtime); len(got) != tc.want {
+				t.Errorf("PreflightKubelet() = %v, want %d hints", got, tc.want)
+			}
+		})
+	}
+}
@@ -18,27 +18,29 @@ limitations under the License.
 package kverify
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
 
-	"github.com/docker/machine/libmachine/state"
 	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 	core "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
-	kconst "k8s.io/kubernetes/cmd/kubeadm/app/constants"
-	"k8s.io/minikube/pkg/minikube/bootstrapper"
-	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
-	"k8s.io/minikube/pkg/minikube/cruntime"
-	"k8s.io/minikube/pkg/minikube/logs"
 )
 
 // minLogCheckTime how long to wait before spamming error logs to console
 const minLogCheckTime = 60 * time.Second
 
+// DefaultWaitTimeout is how long to wait for a component that has no timeout of its own
+const DefaultWaitTimeout = 6 * time.Minute
+
 const (
 	// APIServerWaitKey is the name used in the flags for k8s api server
 	APIServerWaitKey = "apiserver"
@@ -46,6 +48,18 @@ const (
 	SystemPodsWaitKey = "system_pods"
 	// DefaultSAWaitKey is the name used in the flags for default service account
 	DefaultSAWaitKey = "default_sa"
+	// StorageProvisionerWaitKey is the name used in the flags for the storage-provisioner addon pod
+	StorageProvisionerWaitKey = "storage_provisioner"
+	// NodeReadyWaitKey is the name used in the flags for waiting for the nodes to be Ready
+	NodeReadyWaitKey = "node_ready"
+	// DNSWaitKey is the name used in the flags for waiting for cluster DNS to resolve names
+	DNSWaitKey = "dns"
+	// KubeProxyWaitKey is the name used in the flags for waiting for kube-proxy to be ready on every node
+	KubeProxyWaitKey = "kube_proxy"
+	// RegistryWaitKey is the name used in the flags for waiting for the registry addon to serve requests
+	RegistryWaitKey = "registry"
+	// ExtraWaitKey is the alias used in the flags for the default components plus ExtraWaitList
+	ExtraWaitKey = "extra"
 )
 
 //  vars related to the --wait flag
@@ -53,18 +67,59 @@ var (
 	// DefaultComponents is map of the the default components to wait for
 	DefaultComponents = map[string]bool{APIServerWaitKey: true, SystemPodsWaitKey: true}
 	// NoWaitComponents is map of componets to wait for if specified 'none' or 'false'
-	NoComponents = map[string]bool{APIServerWaitKey: false, SystemPodsWaitKey: false, DefaultSAWaitKey: false}
+	NoComponents = map[string]bool{APIServerWaitKey: false, SystemPodsWaitKey: false, DefaultSAWaitKey: false, StorageProvisionerWaitKey: false, NodeReadyWaitKey: false, DNSWaitKey: false, KubeProxyWaitKey: false}
 	// AllComponents is map for waiting for all components.
-	AllComponents = map[string]bool{APIServerWaitKey: true, SystemPodsWaitKey: true, DefaultSAWaitKey: true}
+	AllComponents = map[string]bool{APIServerWaitKey: true, SystemPodsWaitKey: true, DefaultSAWaitKey: true, StorageProvisionerWaitKey: true, NodeReadyWaitKey: true, DNSWaitKey: true, KubeProxyWaitKey: true}
 	// DefaultWaitList is list of all default components to wait for. only names to be used for start flags.
 	DefaultWaitList = []string{APIServerWaitKey, SystemPodsWaitKey}
+	// ExtraWaitList is the list of components the "extra" alias waits for: the defaults, plus node readiness, DNS and the storage-provisioner.
+	ExtraWaitList = []string{APIServerWaitKey, SystemPodsWaitKey, NodeReadyWaitKey, DNSWaitKey, StorageProvisionerWaitKey}
 	// AllComponentsList list of all valid components keys to wait for. only names to be used used for start flags.
-	AllComponentsList = []string{APIServerWaitKey, SystemPodsWaitKey, DefaultSAWaitKey}
+	// The ExtraWaitKey alias is accepted by ValidateWaitComponents but is not itself a component.
+	AllComponentsList = []string{APIServerWaitKey, SystemPodsWaitKey, DefaultSAWaitKey, StorageProvisionerWaitKey, NodeReadyWaitKey, DNSWaitKey, KubeProxyWaitKey}
+	// AddonComponentsList is the list of addon components which may be waited for. They are only waited for when named explicitly, not by 'all'.
+	AddonComponentsList = []string{RegistryWaitKey}
 )
 
+// waitKeys returns every valid component key, including addon components
+func waitKeys() []string {
+	return append(append([]string{}, AllComponentsList...), AddonComponentsList...)
+}
+
+// ValidateWaitComponents parses wait component keys into a map, returning an error naming any unknown keys.
+// The ExtraWaitKey alias expands to every component in ExtraWaitList.
+func ValidateWaitComponents(keys []string) (map[string]bool, error) {
+	components := map[string]bool{}
+	unknown := []string{}
+	for _, k := range keys {
+		if k == ExtraWaitKey {
+			for _, c := range ExtraWaitList {
+				components[c] = true
+			}
+			continue
+		}
+		valid := false
+		for _, c := range waitKeys() {
+			if k == c {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			unknown = append(unknown, k)
+			continue
+		}
+		components[k] = true
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown wait component: %s (valid: %s, %s)", strings.Join(unknown, ", "), strings.Join(waitKeys(), ", "), ExtraWaitKey)
+	}
+	return components, nil
+}
+
 // ShouldWait will return true if the config says need to wait
 func ShouldWait(wcs map[string]bool) bool {
-	for _, c := range AllComponentsList {
+	for _, c := range waitKeys() {
 		if wcs[c] {
 			return true
 		}
@@ -72,48 +127,403 @@ func ShouldWait(wcs map[string]bool) bool {
 	return false
 }
 
+// WaitTimeout returns the timeout configured for the component key, or DefaultWaitTimeout if there is none
+func WaitTimeout(timeouts map[string]time.Duration, key string) time.Duration {
+	if t, ok := timeouts[key]; ok && t > 0 {
+		return t
+	}
+	return DefaultWaitTimeout
+}
+
+// WaitTimeoutsWithFallback returns a copy of timeouts in which every component without a timeout of its own has fallback
+func WaitTimeoutsWithFallback(timeouts map[string]time.Duration, fallback time.Duration) map[string]time.Duration {
+	filled := map[string]time.Duration{}
+	for _, key := range waitKeys() {
+		filled[key] = fallback
+		if t, ok := timeouts[key]; ok && t > 0 {
+			filled[key] = t
+		}
+	}
+	return filled
+}
+
+// LongestWaitTimeout returns the longest of timeouts, or DefaultWaitTimeout if there are none
+func LongestWaitTimeout(timeouts map[string]time.Duration) time.Duration {
+	longest := time.Duration(0)
+	for _, t := range timeouts {
+		if t > longest {
+			longest = t
+		}
+	}
+	if longest == 0 {
+		return DefaultWaitTimeout
+	}
+	return longest
+}
+
+// MissingComponentsError is returned when expected components are not running
+type MissingComponentsError struct {
+	// Components are the names of the components which were not found running
+	Components []string
+	// Nodes maps per-node components to the nodes they were not found running on
+	Nodes map[string][]string
+	// Pods is the status of each kube-system pod at the time of the check, as formatted by podStatusMsg
+	Pods []string
+}
+
+func (e *MissingComponentsError) Error() string {
+	return fmt.Sprintf("missing components: %v", strings.Join(e.Components, ", "))
+}
+
+// Detail returns the error message along with the nodes each component is missing from and the kube-system pods seen, for diagnosing the failure
+func (e *MissingComponentsError) Detail() string {
+	msgs := []string{}
+	for _, c := range e.Components {
+		nodes := e.Nodes[c]
+		switch len(nodes) {
+		case 0:
+			msgs = append(msgs, c)
+		case 1:
+			msgs = append(msgs, fmt.Sprintf("%s (on node %s)", c, nodes[0]))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s (on nodes %s)", c, strings.Join(nodes, ", ")))
+		}
+	}
+	msg := fmt.Sprintf("missing components: %v", strings.Join(msgs, ", "))
+	if len(e.Pods) > 0 {
+		msg = fmt.Sprintf("%s; kube-system pods: %s", msg, strings.Join(e.Pods, "; "))
+	}
+	return msg
+}
+
+// perNodeComponents are the components expected to run on every node
+var perNodeComponents = map[string]bool{
+	"kube-proxy": true,
+}
+
+// controlPlaneComponents are the components expected to run on every control plane node
+var controlPlaneComponents = map[string]bool{
+	"etcd":                    true,
+	"kube-apiserver":          true,
+	"kube-controller-manager": true,
+	"kube-scheduler":          true,
+}
+
+// controlPlaneNodeLabel is the label kubeadm applies to control plane nodes
+const controlPlaneNodeLabel = "node-role.kubernetes.io/master"
+
+// expectedComponents are the control plane components expected to run.
+// kubeadm has deployed the same set for every kubernetes version minikube supports.
+var expectedComponents = []string{
+	dnsComponent,
+	"etcd",
+	"kube-apiserver",
+	"kube-controller-manager",
+	"kube-proxy",
+	"kube-scheduler",
+}
+
+// ErrControlPlaneNotInitialized is returned when the kube-system namespace does not exist yet, as early in bootstrap
+var ErrControlPlaneNotInitialized = errors.New("control plane not yet initialized: kube-system namespace does not exist")
+
+// listAttempts is how many times a failing List is tried before the apiserver is considered unreachable
+const listAttempts = 4
+
+// APIServerUnreachableError is returned when the apiserver could not be listed from, as opposed to components missing
+type APIServerUnreachableError struct {
+	// What is the kind of object that could not be listed
+	What string
+	// Err is the error from the last attempt
+	Err error
+}
+
+func (e *APIServerUnreachableError) Error() string {
+	return fmt.Sprintf("unable to reach apiserver to list %s: %v", e.What, e.Err)
+}
+
+// Unwrap returns the error from the last attempt
+func (e *APIServerUnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// APIServerUnauthorizedError is returned when the apiserver is up, but rejects the credentials used to list from it
+type APIServerUnauthorizedError struct {
+	// What is the kind of object that could not be listed
+	What string
+	// Err is the 401 or 403 error returned by the apiserver
+	Err error
+}
+
+func (e *APIServerUnauthorizedError) Error() string {
+	return fmt.Sprintf("apiserver rejected the credentials used to list %s, the kubeconfig or certificates may be stale (try 'minikube update-context', or 'minikube delete' to regenerate them): %v", e.What, e.Err)
+}
+
+// Unwrap returns the error returned by the apiserver
+func (e *APIServerUnauthorizedError) Unwrap() error {
+	return e.Err
+}
+
+// retryList calls list until it succeeds, backing off between up to listAttempts attempts, as List calls fail briefly while the apiserver restarts.
+// Retrying will not fix rejected credentials, so a 401 or 403 returns an *APIServerUnauthorizedError at once.
+func retryList(ctx context.Context, what string, list func() error) error {
+	b := &pollBackoff{next: retryInterval(ctx)}
+	var err error
+	for attempt := 1; attempt <= listAttempts; attempt++ {
+		if err = list(); err == nil {
+			return nil
+		}
+		if apierr.IsUnauthorized(err) || apierr.IsForbidden(err) {
+			return &APIServerUnauthorizedError{What: what, Err: err}
+		}
+		glog.Infof("list %s failed (attempt %d/%d): %v", what, attempt, listAttempts, err)
+		if attempt == listAttempts {
+			break
+		}
+		if serr := sleep(ctx, b.step()); serr != nil {
+			return errors.Wrapf(serr, "list %s", what)
+		}
+	}
+	return &APIServerUnreachableError{What: what, Err: err}
+}
+
 // ExpectedComponentsRunning returns whether or not all expected components are running
-func ExpectedComponentsRunning(cs *kubernetes.Clientset) error {
-	expected := []string{
-		"kube-dns", // coredns
-		"etcd",
-		"kube-apiserver",
-		"kube-controller-manager",
-		"kube-proxy",
-		"kube-scheduler",
+func ExpectedComponentsRunning(ctx context.Context, cs kubernetes.Interface) error {
+	return ExpectedComponentsRunningWith(ctx, cs, nil)
+}
+
+// ExpectedComponentsRunningWith is like ExpectedComponentsRunning, but also requires the extra components to be running.
+// Extra components are matched against the "component" and "k8s-app" labels of kube-system pods.
+func ExpectedComponentsRunningWith(ctx context.Context, cs kubernetes.Interface, extra []string) error {
+	return expectedComponentsRunning(ctx, cs, extra, nil)
+}
+
+// ExpectedComponentsRunningFor is like ExpectedComponentsRunning, but identifies components using cfg.ControlPlaneLabels.
+// Each key is an expected component, and each value a label selector matching its pods. An empty selector skips the component.
+func ExpectedComponentsRunningFor(ctx context.Context, cs kubernetes.Interface, cfg config.ClusterConfig) error {
+	return expectedComponentsRunning(ctx, cs, nil, cfg.ControlPlaneLabels)
+}
+
+// expectedComponentsRunning returns whether the expected and extra components are running, identifying components by custom label selectors as well as the standard labels
+func expectedComponentsRunning(ctx context.Context, cs kubernetes.Interface, extra []string, custom map[string]string) error {
+	selectors := map[string]labels.Selector{}
+	skipped := map[string]bool{}
+	for c, sel := range custom {
+		if sel == "" {
+			skipped[c] = true
+			continue
+		}
+		s, err := labels.Parse(sel)
+		if err != nil {
+			return errors.Wrapf(err, "custom labels for %s", c)
+		}
+		selectors[c] = s
+	}
+
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "expected components")
 	}
 
+	// copy so expectedComponents is never modified
+	expected := append(append([]string{}, expectedComponents...), extra...)
+
 	found := map[string]bool{}
+	foundOn := map[string]map[string]bool{}
 
-	pods, err := cs.CoreV1().Pods("kube-system").List(meta.ListOptions{})
-	if err != nil {
+	// kube-system only exists once kubeadm has initialized the control plane
+	nsFound := true
+	if err := retryList(ctx, "kube-system namespace", func() error {
+		_, err := cs.CoreV1().Namespaces().Get("kube-system", meta.GetOptions{})
+		if apierr.IsNotFound(err) {
+			nsFound = false
+			return nil
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+	if !nsFound {
+		return ErrControlPlaneNotInitialized
+	}
+
+	var etcdExternal bool
+	if err := retryList(ctx, "kubeadm-config configmap", func() (err error) {
+		etcdExternal, err = usesExternalEtcd(cs)
+		return err
+	}); err != nil {
+		return err
+	}
+	if etcdExternal {
+		glog.Infof("kubeadm cluster configuration uses an external etcd")
+	}
+
+	var pods []core.Pod
+	if err := retryList(ctx, "kube-system pods", func() (err error) {
+		pods, err = systemPods(ctx, cs)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	var nodes *core.NodeList
+	if err := retryList(ctx, "nodes", func() (err error) {
+		nodes, err = cs.CoreV1().Nodes().List(meta.ListOptions{})
+		return err
+	}); err != nil {
 		return err
 	}
 
-	for _, pod := range pods.Items {
-		glog.Infof("found pod: %s", podStatusMsg(pod))
-		if pod.Status.Phase != core.PodRunning {
+	snapshot := []string{}
+	for _, pod := range pods {
+		msg := podStatusMsg(pod)
+		snapshot = append(snapshot, msg)
+		glog.Infof("found pod: %s", msg)
+		if !podRunning(pod) {
 			continue
 		}
+		credit := func(c string) {
+			found[c] = true
+			if foundOn[c] == nil {
+				foundOn[c] = map[string]bool{}
+			}
+			foundOn[c][pod.Spec.NodeName] = true
+		}
 		for k, v := range pod.ObjectMeta.Labels {
 			if k == "component" || k == "k8s-app" {
-				found[v] = true
+				credit(v)
 			}
 		}
+		for c, sel := range selectors {
+			if sel.Matches(labels.Set(pod.ObjectMeta.Labels)) {
+				credit(c)
+			}
+		}
+		if impl := dnsImplementation(pod); impl != "" {
+			glog.Infof("cluster DNS is provided by %s: %s", impl, pod.ObjectMeta.GetName())
+			found[dnsComponent] = true
+		}
+	}
+
+	allNodes := []string{}
+	controlPlanes := []string{}
+	for _, n := range nodes.Items {
+		allNodes = append(allNodes, n.Name)
+		if _, ok := n.ObjectMeta.Labels[controlPlaneNodeLabel]; ok {
+			controlPlanes = append(controlPlanes, n.Name)
+		}
 	}
 
 	missing := []string{}
+	missingOn := map[string][]string{}
 	for _, e := range expected {
+		if e == "etcd" && etcdExternal {
+			continue
+		}
+		if skipped[e] {
+			glog.Infof("skipping %s, as configured by control plane labels", e)
+			continue
+		}
+		// in HA clusters, one running copy of a control plane component is not enough
+		var on []string
+		switch {
+		case perNodeComponents[e]:
+			on = allNodes
+		case controlPlaneComponents[e]:
+			on = controlPlanes
+		}
+		if len(on) > 0 {
+			for _, n := range on {
+				if !foundOn[e][n] {
+					missingOn[e] = append(missingOn[e], n)
+				}
+			}
+			if len(missingOn[e]) > 0 {
+				missing = append(missing, e)
+			}
+			continue
+		}
 		if !found[e] {
 			missing = append(missing, e)
 		}
 	}
 	if len(missing) > 0 {
-		return fmt.Errorf("missing components: %v", strings.Join(missing, ", "))
+		return &MissingComponentsError{Components: missing, Nodes: missingOn, Pods: snapshot}
 	}
 	return nil
 }
 
+// podRunning returns whether or not a pod should be credited as running.
+// Pending pods whose containers are all Ready are about to transition to Running, so they count too.
+func podRunning(pod core.Pod) bool {
+	switch pod.Status.Phase {
+	case core.PodRunning:
+		return true
+	case core.PodPending:
+		return containersReady(pod)
+	}
+	return false
+}
+
+// containersReady returns whether or not all of the pod's containers report Ready
+func containersReady(pod core.Pod) bool {
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return false
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if !cs.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// usesExternalEtcd returns whether or not the kubeadm cluster configuration points at an external etcd
+func usesExternalEtcd(cs kubernetes.Interface) (bool, error) {
+	cm, err := cs.CoreV1().ConfigMaps("kube-system").Get("kubeadm-config", meta.GetOptions{})
+	if apierr.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "get kubeadm-config configmap")
+	}
+	return externalEtcd(cm.Data["ClusterConfiguration"])
+}
+
+// externalEtcd returns whether or not a kubeadm ClusterConfiguration document sets etcd.external
+func externalEtcd(conf string) (bool, error) {
+	var cc struct {
+		Etcd struct {
+			External *struct {
+				Endpoints []string `yaml:"endpoints"`
+			} `yaml:"external"`
+		} `yaml:"etcd"`
+	}
+	if err := yaml.Unmarshal([]byte(conf), &cc); err != nil {
+		return false, errors.Wrap(err, "parse kubeadm cluster configuration")
+	}
+	return cc.Etcd.External != nil, nil
+}
+
+// dnsComponent is the name used for cluster DNS in the expected components, regardless of implementation
+const dnsComponent = "kube-dns"
+
+// dnsImplementation returns which cluster DNS implementation a pod belongs to ("coredns" or "kube-dns"), or "" if none.
+// The k8s-app label alone is unreliable, as coredns is labeled "kube-dns" for compatibility.
+func dnsImplementation(pod core.Pod) string {
+	for _, c := range pod.Spec.Containers {
+		switch {
+		case strings.Contains(c.Image, "coredns"):
+			return "coredns"
+		case strings.Contains(c.Image, "kube-dns"):
+			return "kube-dns"
+		}
+	}
+	switch v := pod.ObjectMeta.Labels["k8s-app"]; v {
+	case "coredns", "kube-dns":
+		return v
+	}
+	return ""
+}
+
 // podStatusMsg returns a human-readable pod status, for generating debug status
 func podStatusMsg(pod core.Pod) string {
 	var sb strings.Builder
@@ -131,35 +541,43 @@ func podStatusMsg(pod core.Pod) string {
 			sb.WriteString(fmt.Sprintf(" (%s)", c.Message))
 		}
 	}
+	for _, c := range pod.Status.ContainerStatuses {
+		sb.WriteString(fmt.Sprintf(" | %s ready=%t restarts=%d", c.Name, c.Ready, c.RestartCount))
+		if w := c.State.Waiting; w != nil && w.Reason != "" {
+			sb.WriteString(fmt.Sprintf(" waiting:%s", w.Reason))
+		}
+		if t := c.State.Terminated; t != nil && t.Reason != "" {
+			sb.WriteString(fmt.Sprintf(" terminated:%s", t.Reason))
+		}
+	}
 	return sb.String()
 }
 
-// announceProblems checks for problems, and slows polling down if any are found
-func announceProblems(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr command.Runner) {
-	problems := logs.FindProblems(r, bs, cfg, cr)
-	if len(problems) > 0 {
-		logs.OutputProblems(problems, 5)
-		time.Sleep(kconst.APICallRetryInterval * 15)
-	}
+// podStatus is the machine-readable form of podStatusMsg
+type podStatus struct {
+	Name       string               `json:"name"`
+	UID        string               `json:"uid"`
+	Phase      string               `json:"phase"`
+	Conditions []podStatusCondition `json:"conditions"`
 }
 
-// KubeletStatus checks the kubelet status
-func KubeletStatus(cr command.Runner) (state.State, error) {
-	glog.Infof("Checking kubelet status ...")
-	rr, err := cr.RunCmd(exec.Command("sudo", "systemctl", "is-active", "kubelet"))
-	if err != nil {
-		// Do not return now, as we still have parsing to do!
-		glog.Warningf("%s returned error: %v", rr.Command(), err)
-	}
-	s := strings.TrimSpace(rr.Stdout.String())
-	glog.Infof("kubelet is-active: %s", s)
-	switch s {
-	case "active":
-		return state.Running, nil
-	case "inactive":
-		return state.Stopped, nil
-	case "activating":
-		return state.Starting, nil
-	}
-	return state.Error, nil
+// podStatusCondition is a pod condition within podStatus
+type podStatusCondition struct {
+	Type    string `json:"type"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// PodStatusJSON returns a JSON pod status, for generating machine-readable debug status
+func PodStatusJSON(pod core.Pod) ([]byte, error) {
+	ps := podStatus{
+		Name:       pod.ObjectMeta.GetName(),
+		UID:        string(pod.ObjectMeta.GetUID()),
+		Phase:      string(pod.Status.Phase),
+		Conditions: []podStatusCondition{},
+	}
+	for _, c := range pod.Status.Conditions {
+		ps.Conditions = append(ps.Conditions, podStatusCondition{Type: string(c.Type), Reason: c.Reason, Message: c.Message})
+	}
+	return json.Marshal(ps)
 }
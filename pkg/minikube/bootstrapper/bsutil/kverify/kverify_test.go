@@ -0,0 +1,271 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMissingComponentsError(t *testing.T) {
+	var err error = &MissingComponentsError{Components: []string{"etcd", "kube-apiserver"}}
+
+	want := "missing components: etcd, kube-apiserver"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	var mce *MissingComponentsError
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &mce) {
+		t.Fatalf("errors.As(%v) = false, want true", err)
+	}
+	if len(mce.Components) != 2 || mce.Components[0] != "etcd" {
+		t.Errorf("Components = %v, want [etcd kube-apiserver]", mce.Components)
+	}
+
+	err = &MissingComponentsError{
+		Components: []string{"etcd", "kube-proxy"},
+		Nodes:      map[string][]string{"kube-proxy": {"m02"}},
+	}
+	if want := "missing components: etcd, kube-proxy"; err.Error() != want {
+		t.Errorf("Error() = %q, want the unchanged %q", err.Error(), want)
+	}
+
+	var tests = []struct {
+		err  *MissingComponentsError
+		want string
+	}{
+		{&MissingComponentsError{Components: []string{"etcd", "kube-proxy"}, Nodes: map[string][]string{"kube-proxy": {"m02"}}},
+			"missing components: etcd, kube-proxy (on node m02)"},
+		{&MissingComponentsError{Components: []string{"kube-proxy"}, Nodes: map[string][]string{"kube-proxy": {"m02", "m03"}}},
+			"missing components: kube-proxy (on nodes m02, m03)"},
+		{&MissingComponentsError{Components: []string{"etcd"}, Pods: []string{`"kube-apiserver-minikube" [1] Running`, `"etcd-minikube" [2] Pending`}},
+			`missing components: etcd; kube-system pods: "kube-apiserver-minikube" [1] Running; "etcd-minikube" [2] Pending`},
+	}
+	for _, tc := range tests {
+		if got := tc.err.Detail(); got != tc.want {
+			t.Errorf("Detail() = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestDNSImplementation(t *testing.T) {
+	var tests = []struct {
+		name   string
+		labels map[string]string
+		image  string
+		want   string
+	}{
+		{"coredns labeled kube-dns", map[string]string{"k8s-app": "kube-dns"}, "k8s.gcr.io/coredns:1.6.5", "coredns"},
+		{"coredns labeled coredns", map[string]string{"k8s-app": "coredns"}, "k8s.gcr.io/coredns:1.6.5", "coredns"},
+		{"legacy kube-dns", map[string]string{"k8s-app": "kube-dns"}, "k8s.gcr.io/k8s-dns-kube-dns-amd64:1.14.13", "kube-dns"},
+		{"custom image labeled coredns", map[string]string{"k8s-app": "coredns"}, "example.com/dns:latest", "coredns"},
+		{"not dns", map[string]string{"component": "etcd"}, "k8s.gcr.io/etcd:3.4.3-0", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := core.Pod{
+				ObjectMeta: meta.ObjectMeta{Labels: tc.labels},
+				Spec:       core.PodSpec{Containers: []core.Container{{Image: tc.image}}},
+			}
+			got := dnsImplementation(pod)
+			if got != tc.want {
+				t.Errorf("dnsImplementation() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExternalEtcd(t *testing.T) {
+	var tests = []struct {
+		name    string
+		conf    string
+		want    bool
+		wantErr bool
+	}{
+		{"empty", "", false, false},
+		{"stacked", "etcd:\n  local:\n    dataDir: /var/lib/minikube/etcd\n", false, false},
+		{"external", "etcd:\n  external:\n    endpoints:\n    - https://10.0.0.5:2379\n", true, false},
+		{"invalid", "etcd: [", false, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := externalEtcd(tc.conf)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("externalEtcd() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("externalEtcd() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPodStatusJSON(t *testing.T) {
+	pod := core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: "etcd-minikube", UID: "1234"},
+		Status: core.PodStatus{
+			Phase:      core.PodPending,
+			Conditions: []core.PodCondition{{Type: core.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}},
+		},
+	}
+	got, err := PodStatusJSON(pod)
+	if err != nil {
+		t.Fatalf("PodStatusJSON: %v", err)
+	}
+	want := `{"name":"etcd-minikube","uid":"1234","phase":"Pending","conditions":[{"type":"PodScheduled","reason":"Unschedulable","message":"0/1 nodes are available"}]}`
+	if string(got) != want {
+		t.Errorf("PodStatusJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestPodRunning(t *testing.T) {
+	var tests = []struct {
+		name  string
+		phase core.PodPhase
+		ready []bool
+		want  bool
+	}{
+		{"running", core.PodRunning, []bool{false}, true},
+		{"pending and ready", core.PodPending, []bool{true, true}, true},
+		{"pending and partly ready", core.PodPending, []bool{true, false}, false},
+		{"pending without containers", core.PodPending, nil, false},
+		{"succeeded", core.PodSucceeded, []bool{true}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := core.Pod{Status: core.PodStatus{Phase: tc.phase}}
+			for _, r := range tc.ready {
+				pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, core.ContainerStatus{Ready: r})
+			}
+			if got := podRunning(pod); got != tc.want {
+				t.Errorf("podRunning() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWaitTimeoutsWithFallback(t *testing.T) {
+	got := WaitTimeoutsWithFallback(map[string]time.Duration{SystemPodsWaitKey: 10 * time.Minute, "bogus": time.Hour}, 15*time.Minute)
+	if got[APIServerWaitKey] != 15*time.Minute {
+		t.Errorf("apiserver timeout = %s, want the 15m fallback", got[APIServerWaitKey])
+	}
+	if got[SystemPodsWaitKey] != 10*time.Minute {
+		t.Errorf("system_pods timeout = %s, want its own 10m", got[SystemPodsWaitKey])
+	}
+	if _, ok := got["bogus"]; ok {
+		t.Errorf("unknown component was kept: %v", got)
+	}
+	if l := LongestWaitTimeout(got); l != 15*time.Minute {
+		t.Errorf("LongestWaitTimeout() = %s, want 15m", l)
+	}
+	if l := LongestWaitTimeout(nil); l != DefaultWaitTimeout {
+		t.Errorf("LongestWaitTimeout(nil) = %s, want %s", l, DefaultWaitTimeout)
+	}
+}
+
+func TestValidateWaitComponents(t *testing.T) {
+	got, err := ValidateWaitComponents([]string{APIServerWaitKey, DefaultSAWaitKey})
+	if err != nil {
+		t.Fatalf("ValidateWaitComponents() error = %v", err)
+	}
+	if len(got) != 2 || !got[APIServerWaitKey] || !got[DefaultSAWaitKey] {
+		t.Errorf("ValidateWaitComponents() = %v, want apiserver and default_sa", got)
+	}
+
+	_, err = ValidateWaitComponents([]string{"foo", APIServerWaitKey})
+	want := "unknown wait component: foo (valid: apiserver, system_pods, default_sa, storage_provisioner, node_ready, dns, kube_proxy, registry, extra)"
+	if err == nil || err.Error() != want {
+		t.Errorf("ValidateWaitComponents() error = %v, want %q", err, want)
+	}
+
+	got, err = ValidateWaitComponents([]string{ExtraWaitKey, DefaultSAWaitKey})
+	if err != nil {
+		t.Fatalf("ValidateWaitComponents() error = %v", err)
+	}
+	for _, k := range append(ExtraWaitList, DefaultSAWaitKey) {
+		if !got[k] {
+			t.Errorf("ValidateWaitComponents(extra) = %v, missing %s", got, k)
+		}
+	}
+}
+
+func TestPodStatusMsg(t *testing.T) {
+	pod := core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: "kube-apiserver-minikube", UID: "1234"},
+		Status: core.PodStatus{
+			Phase: core.PodRunning,
+			Conditions: []core.PodCondition{
+				{Type: core.ContainersReady, Status: core.ConditionFalse, Reason: "ContainersNotReady"},
+			},
+			ContainerStatuses: []core.ContainerStatus{
+				{Name: "kube-apiserver", Ready: false, RestartCount: 3, State: core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+				{Name: "sidecar", Ready: false, RestartCount: 0, State: core.ContainerState{Terminated: &core.ContainerStateTerminated{Reason: "Error"}}},
+			},
+		},
+	}
+	want := `"kube-apiserver-minikube" [1234] Running: ContainersReady:ContainersNotReady | kube-apiserver ready=false restarts=3 waiting:CrashLoopBackOff | sidecar ready=false restarts=0 terminated:Error`
+	if got := podStatusMsg(pod); got != want {
+		t.Errorf("podStatusMsg() = %q, want %q", got, want)
+	}
+}
+
+func TestRetryList(t *testing.T) {
+	_, restore := useFakeClock()
+	defer restore()
+
+	calls := 0
+	err := retryList(context.Background(), "pods", func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("retryList() = %v after %d calls, want success after 3", err, calls)
+	}
+
+	calls = 0
+	err = retryList(context.Background(), "pods", func() error {
+		calls++
+		return fmt.Errorf("connection refused")
+	})
+	var ue *APIServerUnreachableError
+	if !errors.As(err, &ue) || calls != listAttempts {
+		t.Errorf("retryList() = %v after %d calls, want *APIServerUnreachableError after %d", err, calls, listAttempts)
+	}
+
+	for _, rejected := range []error{apierr.NewUnauthorized("Unauthorized"), apierr.NewForbidden(core.Resource("pods"), "", fmt.Errorf("RBAC: access denied"))} {
+		calls = 0
+		err = retryList(context.Background(), "pods", func() error {
+			calls++
+			return rejected
+		})
+		var uae *APIServerUnauthorizedError
+		if !errors.As(err, &uae) || calls != 1 {
+			t.Errorf("retryList() = %v after %d calls, want *APIServerUnauthorizedError after 1", err, calls)
+		}
+	}
+}
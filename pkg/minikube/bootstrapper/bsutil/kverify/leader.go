@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	coordination "k8s.io/api/coordination/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// leaderElectedComponents are the control plane components which must hold a leader lock to do any work
+var leaderElectedComponents = []string{"kube-controller-manager", "kube-scheduler"}
+
+// DefaultMaxLeaseAge is how long ago a leader may have last renewed its Lease. The renew time is set by the guest clock,
+// so this allows for up to DefaultMaxClockSkew on top of the lease duration controllers use.
+var DefaultMaxLeaseAge = time.Minute + DefaultMaxClockSkew
+
+// VerifyLeaderElection returns an error naming any leader-elected control plane component without a leader, or whose leader has stopped renewing its Lease
+func VerifyLeaderElection(cs kubernetes.Interface) error {
+	for _, c := range leaderElectedComponents {
+		holder, err := leaderHolder(cs, c)
+		if err != nil {
+			return errors.Wrapf(err, "%s leader", c)
+		}
+		if holder == "" {
+			return fmt.Errorf("%s has no leader", c)
+		}
+		glog.Infof("%s leader is %s", c, holder)
+		// older versions lock with Endpoints, which have no Lease to check
+		if err := VerifyLeaseFresh(cs, "kube-system", c, DefaultMaxLeaseAge); err != nil && !apierr.IsNotFound(errors.Cause(err)) {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyLeaseFresh returns an error if a Lease was last renewed more than maxAge ago, as a holder which stopped renewing it is hung
+func VerifyLeaseFresh(cs kubernetes.Interface, ns string, name string, maxAge time.Duration) error {
+	lease, err := cs.CoordinationV1().Leases(ns).Get(name, meta.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "get lease %s/%s", ns, name)
+	}
+	age, err := leaseAge(lease, clk.Now())
+	if err != nil {
+		return errors.Wrapf(err, "lease %s/%s", ns, name)
+	}
+	if age > maxAge {
+		return fmt.Errorf("lease %s/%s held by %s was last renewed %s ago, more than %s: its holder may be hung", ns, name, leaseHolder(lease), age.Round(time.Second), maxAge)
+	}
+	glog.Infof("lease %s/%s was renewed %s ago", ns, name, age.Round(time.Millisecond))
+	return nil
+}
+
+// leaseAge returns how long before now a Lease was last renewed
+func leaseAge(lease *coordination.Lease, now time.Time) (time.Duration, error) {
+	if lease.Spec.RenewTime == nil {
+		return 0, fmt.Errorf("has never been renewed")
+	}
+	return now.Sub(lease.Spec.RenewTime.Time), nil
+}
+
+// leaseHolder returns the holder identity of a Lease, or "nobody"
+func leaseHolder(lease *coordination.Lease) string {
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+		return "nobody"
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+// leaderHolder returns the holder of the leader lock for a kube-system component, checking the Lease first and then the Endpoints annotation
+func leaderHolder(cs kubernetes.Interface, name string) (string, error) {
+	lease, err := cs.CoordinationV1().Leases("kube-system").Get(name, meta.GetOptions{})
+	if err == nil && lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != "" {
+		return *lease.Spec.HolderIdentity, nil
+	}
+	if err != nil && !apierr.IsNotFound(err) {
+		return "", errors.Wrap(err, "get lease")
+	}
+
+	ep, err := cs.CoreV1().Endpoints("kube-system").Get(name, meta.GetOptions{})
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "get endpoints")
+	}
+	return parseLeaderAnnotation(ep.ObjectMeta.Annotations[resourcelock.LeaderElectionRecordAnnotationKey])
+}
+
+// parseLeaderAnnotation returns the holder identity from a leader election record annotation
+func parseLeaderAnnotation(record string) (string, error) {
+	if record == "" {
+		return "", nil
+	}
+	var ler resourcelock.LeaderElectionRecord
+	if err := json.Unmarshal([]byte(record), &ler); err != nil {
+		return "", errors.Wrap(err, "unmarshal leader election record")
+	}
+	return ler.HolderIdentity, nil
+}
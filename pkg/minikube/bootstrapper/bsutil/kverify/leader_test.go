@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+	"time"
+
+	coordination "k8s.io/api/coordination/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseLeaderAnnotation(t *testing.T) {
+	var tests = []struct {
+		name    string
+		record  string
+		want    string
+		wantErr bool
+	}{
+		{"held", `{"holderIdentity":"minikube_5b6c","leaseDurationSeconds":15,"acquireTime":"2020-04-01T00:00:00Z","renewTime":"2020-04-01T00:01:00Z","leaderTransitions":0}`, "minikube_5b6c", false},
+		{"released", `{"holderIdentity":"","leaseDurationSeconds":15}`, "", false},
+		{"missing", "", "", false},
+		{"garbage", "{", "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseLeaderAnnotation(tc.record)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseLeaderAnnotation() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseLeaderAnnotation() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLeaseAge(t *testing.T) {
+	now := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	renewed := meta.NewMicroTime(now.Add(-10 * time.Second))
+	got, err := leaseAge(&coordination.Lease{Spec: coordination.LeaseSpec{RenewTime: &renewed}}, now)
+	if err != nil || got != 10*time.Second {
+		t.Errorf("leaseAge() = %s, %v, want 10s", got, err)
+	}
+	if _, err := leaseAge(&coordination.Lease{}, now); err == nil {
+		t.Errorf("leaseAge() of a lease never renewed returned no error")
+	}
+}
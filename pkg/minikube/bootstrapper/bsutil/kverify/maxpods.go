@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/minikube/pkg/minikube/out"
+)
+
+// minPodHeadroom is the number of free pod slots on a node below which a warning is shown
+const minPodHeadroom = 5
+
+// warnPodHeadroom warns about each node whose pod capacity is nearly used up, as pods which can not be scheduled for that reason look like a scheduler failure
+func warnPodHeadroom(cs kubernetes.Interface, nodeNames []string) {
+	for _, name := range nodeNames {
+		msg, err := podHeadroom(cs, name)
+		if err != nil {
+			glog.Infof("unable to check pod capacity of %q: %v", name, err)
+			continue
+		}
+		if msg != "" {
+			glog.Warningf("node %q: %s", name, msg)
+			out.WarningT("Node {{.name}} can run few more pods: {{.msg}}", out.V{"name": name, "msg": msg})
+		}
+	}
+}
+
+// podHeadroom returns a description of the pod capacity of a node if fewer than minPodHeadroom pods may still be scheduled to it, or ""
+func podHeadroom(cs kubernetes.Interface, nodeName string) (string, error) {
+	n, err := cs.CoreV1().Nodes().Get(nodeName, meta.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	pods, err := cs.CoreV1().Pods("").List(meta.ListOptions{FieldSelector: "spec.nodeName=" + nodeName})
+	if err != nil {
+		return "", err
+	}
+	allocatable, free := freePodSlots(n, pods.Items)
+	if free >= minPodHeadroom {
+		return "", nil
+	}
+	msg := fmt.Sprintf("%d of %d pods scheduled", allocatable-free, allocatable)
+	if cz, err := kubeletConfig(cs, nodeName); err == nil && cz.KubeletConfig.MaxPods > 0 {
+		msg = fmt.Sprintf("%s (kubelet maxPods is %d, raise it with --extra-config=kubelet.max-pods)", msg, cz.KubeletConfig.MaxPods)
+	}
+	return msg, nil
+}
+
+// freePodSlots returns how many pods may be allocated to a node, and how many more may be scheduled besides the non-terminated pods already on it
+func freePodSlots(n *core.Node, pods []core.Pod) (int, int) {
+	q := n.Status.Allocatable[core.ResourcePods]
+	allocatable := int(q.Value())
+	used := 0
+	for _, pod := range pods {
+		if pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+			continue
+		}
+		used++
+	}
+	return allocatable, allocatable - used
+}
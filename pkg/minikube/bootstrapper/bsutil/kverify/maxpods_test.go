@@ -0,0 +1,37 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestFreePodSlots(t *testing.T) {
+	n := &core.Node{Status: core.NodeStatus{Allocatable: core.ResourceList{core.ResourcePods: resource.MustParse("10")}}}
+	pods := []core.Pod{
+		{Status: core.PodStatus{Phase: core.PodRunning}},
+		{Status: core.PodStatus{Phase: core.PodPending}},
+		{Status: core.PodStatus{Phase: core.PodSucceeded}},
+		{Status: core.PodStatus{Phase: core.PodFailed}},
+	}
+	if allocatable, free := freePodSlots(n, pods); allocatable != 10 || free != 8 {
+		t.Errorf("freePodSlots() = %d, %d, want 10, 8", allocatable, free)
+	}
+}
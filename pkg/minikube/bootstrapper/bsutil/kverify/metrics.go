@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// metricsAPIServicePath is the path of the APIService registered by metrics-server, which `kubectl top` depends on
+const metricsAPIServicePath = "/apis/apiregistration.k8s.io/v1/apiservices/v1beta1.metrics.k8s.io"
+
+// apiServiceStatus is the part of an apiregistration.k8s.io APIService needed to tell whether it is available
+type apiServiceStatus struct {
+	Status struct {
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// WaitForMetricsServer waits for the metrics.k8s.io APIService to report Available, so that `kubectl top` works
+func WaitForMetricsServer(ctx context.Context, cs kubernetes.Interface, timeout time.Duration) error {
+	glog.Infof("waiting for the metrics.k8s.io APIService to be available ...")
+	start := clk.Now()
+
+	last := "APIService was never found"
+	available := func() (bool, error) {
+		raw, err := cs.Discovery().RESTClient().Get().AbsPath(metricsAPIServicePath).DoRaw()
+		if err != nil {
+			glog.Infof("temporary error getting the metrics APIService: %v", err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg, err := apiServiceAvailable(raw)
+		if err != nil {
+			return false, err
+		}
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, available); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "metrics APIService")
+		}
+		return fmt.Errorf("metrics.k8s.io APIService never became available: %s", last)
+	}
+	glog.Infof("duration metric: took %s for the metrics APIService to be available ...", since(start))
+	return nil
+}
+
+// apiServiceAvailable returns whether the APIService in raw has the Available condition, and a description of it
+func apiServiceAvailable(raw []byte) (bool, string, error) {
+	var s apiServiceStatus
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, "", errors.Wrap(err, "parse APIService")
+	}
+	for _, c := range s.Status.Conditions {
+		if c.Type != "Available" {
+			continue
+		}
+		if c.Status == "True" {
+			return true, "Available", nil
+		}
+		return false, fmt.Sprintf("Available=%s: %s (%s)", c.Status, c.Reason, c.Message), nil
+	}
+	return false, "APIService has no Available condition", nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import "testing"
+
+func TestAPIServiceAvailable(t *testing.T) {
+	var tests = []struct {
+		name    string
+		raw     string
+		want    bool
+		wantMsg string
+		wantErr bool
+	}{
+		{"available", `{"status":{"conditions":[{"type":"Available","status":"True","reason":"Passed"}]}}`, true, "Available", false},
+		{"missing endpoints", `{"status":{"conditions":[{"type":"Available","status":"False","reason":"MissingEndpoints","message":"endpoints for service/metrics-server in \"kube-system\" have no addresses"}]}}`, false, `Available=False: MissingEndpoints (endpoints for service/metrics-server in "kube-system" have no addresses)`, false},
+		{"no conditions", `{"status":{}}`, false, "APIService has no Available condition", false},
+		{"garbage", `{`, false, "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, msg, err := apiServiceAvailable([]byte(tc.raw))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("apiServiceAvailable() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want || msg != tc.wantMsg {
+				t.Errorf("apiServiceAvailable() = %v, %q, want %v, %q", got, msg, tc.want, tc.wantMsg)
+			}
+		})
+	}
+}
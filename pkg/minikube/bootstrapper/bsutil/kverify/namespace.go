@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForNamespaceActive waits for a namespace to exist and be in the Active phase
+func WaitForNamespaceActive(ctx context.Context, cs kubernetes.Interface, name string, timeout time.Duration) error {
+	glog.Infof("waiting for namespace %q to be active ...", name)
+	start := clk.Now()
+
+	last := "namespace was never found"
+	active := func() (bool, error) {
+		ns, err := cs.CoreV1().Namespaces().Get(name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting namespace %q: %v", name, err)
+			last = err.Error()
+			return false, nil
+		}
+		last = fmt.Sprintf("phase %q", ns.Status.Phase)
+		return ns.Status.Phase == core.NamespaceActive, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, active); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "namespace %q", name)
+		}
+		return fmt.Errorf("namespace %q never became active: %s", name, last)
+	}
+	glog.Infof("duration metric: took %s for namespace %q to be active ...", since(start), name)
+	return nil
+}
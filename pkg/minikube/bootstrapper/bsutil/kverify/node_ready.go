@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForNodeReady waits for the node to report the Ready condition
+func WaitForNodeReady(ctx context.Context, cs kubernetes.Interface, nodeName string, timeout time.Duration) error {
+	glog.Infof("waiting for node %q to be Ready ...", nodeName)
+	start := clk.Now()
+
+	last := "node was never found"
+	nodeReady := func() (bool, error) {
+		n, err := cs.CoreV1().Nodes().Get(nodeName, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("error getting node %q: %v", nodeName, err)
+			last = err.Error()
+			return false, nil
+		}
+		for _, c := range n.Status.Conditions {
+			if c.Type != core.NodeReady {
+				continue
+			}
+			if c.Status == core.ConditionTrue {
+				return true, nil
+			}
+			last = fmt.Sprintf("Ready=%s: %s (%s)", c.Status, c.Reason, c.Message)
+			glog.Infof("node %q has status %s", nodeName, last)
+			return false, nil
+		}
+		last = "node has no Ready condition"
+		return false, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, nodeReady); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "node %q ready", nodeName)
+		}
+		return fmt.Errorf("node %q never became Ready: %s", nodeName, last)
+	}
+	glog.Infof("duration metric: took %s for node %q to be Ready ...", since(start), nodeName)
+	return nil
+}
+
+// WaitForNodesReady waits for each of the nodes to report the Ready condition, checking at most MaxConcurrentWaits at once
+func WaitForNodesReady(ctx context.Context, cs kubernetes.Interface, nodeNames []string, timeout time.Duration) error {
+	return runLimited(ctx, nodeNames, MaxConcurrentWaits, func(ctx context.Context, name string) error {
+		return WaitForNodeReady(ctx, cs, name, timeout)
+	})
+}
+
+// controlPlaneTaint is the taint kubeadm may put on control plane nodes. minikube registers nodes without it, so that a single node can run workloads.
+const controlPlaneTaint = "node-role.kubernetes.io/master"
+
+// WaitForTaintRemoved waits for the node to no longer carry a taint with the given key
+func WaitForTaintRemoved(ctx context.Context, cs kubernetes.Interface, nodeName string, taintKey string, timeout time.Duration) error {
+	glog.Infof("waiting for taint %s to be removed from node %q ...", taintKey, nodeName)
+	start := clk.Now()
+
+	last := "node was never found"
+	untainted := func() (bool, error) {
+		n, err := cs.CoreV1().Nodes().Get(nodeName, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("error getting node %q: %v", nodeName, err)
+			last = err.Error()
+			return false, nil
+		}
+		if t := findTaint(n, taintKey); t != nil {
+			last = fmt.Sprintf("still tainted %s", t.ToString())
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, untainted); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "node %q taint %s", nodeName, taintKey)
+		}
+		return fmt.Errorf("node %q never had taint %s removed: %s", nodeName, taintKey, last)
+	}
+	glog.Infof("duration metric: took %s for taint %s to be removed from node %q ...", since(start), taintKey, nodeName)
+	return nil
+}
+
+// findTaint returns the node's taint with the given key, or nil if it has none
+func findTaint(n *core.Node, key string) *core.Taint {
+	for i := range n.Spec.Taints {
+		if n.Spec.Taints[i].Key == key {
+			return &n.Spec.Taints[i]
+		}
+	}
+	return nil
+}
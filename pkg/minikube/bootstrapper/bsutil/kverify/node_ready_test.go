@@ -0,0 +1,36 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+)
+
+func TestFindTaint(t *testing.T) {
+	n := &core.Node{Spec: core.NodeSpec{Taints: []core.Taint{
+		{Key: "node.kubernetes.io/not-ready", Effect: core.TaintEffectNoSchedule},
+		{Key: controlPlaneTaint, Effect: core.TaintEffectNoSchedule},
+	}}}
+	if got := findTaint(n, controlPlaneTaint); got == nil || got.Effect != core.TaintEffectNoSchedule {
+		t.Errorf("findTaint() = %v, want the control plane taint", got)
+	}
+	if got := findTaint(&core.Node{}, controlPlaneTaint); got != nil {
+		t.Errorf("findTaint(untainted) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// VerifyPodCIDR returns an error if any node was allocated a pod CIDR outside of the expected cluster pod network
+func VerifyPodCIDR(cs kubernetes.Interface, expected string) error {
+	_, cluster, err := net.ParseCIDR(expected)
+	if err != nil {
+		return errors.Wrapf(err, "parse expected pod CIDR %q", expected)
+	}
+
+	nodes, err := cs.CoreV1().Nodes().List(meta.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "list nodes")
+	}
+
+	for _, n := range nodes.Items {
+		if n.Spec.PodCIDR == "" {
+			return fmt.Errorf("node %s has not been allocated a pod CIDR, expected one within %s", n.Name, expected)
+		}
+		ok, err := cidrContains(cluster, n.Spec.PodCIDR)
+		if err != nil {
+			return errors.Wrapf(err, "node %s", n.Name)
+		}
+		if !ok {
+			return fmt.Errorf("node %s pod CIDR %s is not within the requested pod network %s", n.Name, n.Spec.PodCIDR, expected)
+		}
+		glog.Infof("node %s pod CIDR %s is within %s", n.Name, n.Spec.PodCIDR, expected)
+	}
+	return nil
+}
+
+// cidrContains returns whether subnet lies entirely within network
+func cidrContains(network *net.IPNet, subnet string) (bool, error) {
+	ip, sub, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return false, errors.Wrapf(err, "parse pod CIDR %q", subnet)
+	}
+	netOnes, netBits := network.Mask.Size()
+	subOnes, subBits := sub.Mask.Size()
+	return netBits == subBits && subOnes >= netOnes && network.Contains(ip), nil
+}
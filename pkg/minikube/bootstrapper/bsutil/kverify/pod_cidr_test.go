@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"net"
+	"testing"
+)
+
+func TestCIDRContains(t *testing.T) {
+	var tests = []struct {
+		network string
+		subnet  string
+		want    bool
+		wantErr bool
+	}{
+		{"10.244.0.0/16", "10.244.0.0/24", true, false},
+		{"10.244.0.0/16", "10.244.3.0/24", true, false},
+		{"10.244.0.0/16", "10.244.0.0/16", true, false},
+		{"10.244.0.0/16", "10.245.0.0/24", false, false},
+		{"10.244.0.0/16", "10.0.0.0/8", false, false},
+		{"10.244.0.0/16", "fd00::/64", false, false},
+		{"10.244.0.0/16", "garbage", false, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.subnet, func(t *testing.T) {
+			_, network, err := net.ParseCIDR(tc.network)
+			if err != nil {
+				t.Fatalf("ParseCIDR(%q): %v", tc.network, err)
+			}
+			got, err := cidrContains(network, tc.subnet)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("cidrContains() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("cidrContains(%s, %s) = %v, want %v", tc.network, tc.subnet, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// listerSyncTimeout is how long to wait for a new pod informer to sync before falling back to a plain List
+const listerSyncTimeout = 30 * time.Second
+
+// podCacheKey is the context key for the podCache
+type podCacheKey struct{}
+
+// podCache holds the kube-system pod listers started under one WithSystemPodCache context, one per clientset
+type podCache struct {
+	sync.Mutex
+	m map[kubernetes.Interface]*podListerEntry
+}
+
+// podListerEntry is a pod lister and the informer factory feeding it
+type podListerEntry struct {
+	factory informers.SharedInformerFactory
+	lister  corelisters.PodLister
+	stop    chan struct{}
+}
+
+// WithSystemPodCache returns a copy of ctx under which kube-system pods are served from informer caches, so that polling does not re-list every call,
+// along with a func which stops the informers. Without it, kube-system pods are listed directly.
+func WithSystemPodCache(ctx context.Context) (context.Context, func()) {
+	c := &podCache{m: map[kubernetes.Interface]*podListerEntry{}}
+	return context.WithValue(ctx, podCacheKey{}, c), c.release
+}
+
+// systemPodCache returns the podCache carried by ctx, or nil
+func systemPodCache(ctx context.Context) *podCache {
+	c, _ := ctx.Value(podCacheKey{}).(*podCache)
+	return c
+}
+
+// lister returns a synced kube-system pod lister for cs, starting an informer on first use.
+// The sync is waited for without holding the cache lock, so that a slow apiserver does not block waiters of other clusters.
+func (c *podCache) lister(ctx context.Context, cs kubernetes.Interface) (corelisters.PodLister, error) {
+	c.Lock()
+	e, ok := c.m[cs]
+	if !ok {
+		factory := informers.NewSharedInformerFactoryWithOptions(cs, 0, informers.WithNamespace("kube-system"))
+		e = &podListerEntry{factory: factory, lister: factory.Core().V1().Pods().Lister(), stop: make(chan struct{})}
+		factory.Start(e.stop)
+		c.m[cs] = e
+	}
+	c.Unlock()
+
+	sctx, cancel := context.WithTimeout(ctx, listerSyncTimeout)
+	defer cancel()
+	// stop waiting if the informer is released while syncing
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sctx.Done():
+		case <-e.stop:
+		}
+		close(done)
+	}()
+	for typ, synced := range e.factory.WaitForCacheSync(done) {
+		if !synced {
+			c.releaseEntry(cs, e)
+			return nil, fmt.Errorf("informer for %v never synced", typ)
+		}
+	}
+	return e.lister, nil
+}
+
+// release stops every informer started by c
+func (c *podCache) release() {
+	c.Lock()
+	defer c.Unlock()
+	for cs, e := range c.m {
+		delete(c.m, cs)
+		close(e.stop)
+	}
+}
+
+// releaseEntry stops the informer of e and forgets it, unless it was already released
+func (c *podCache) releaseEntry(cs kubernetes.Interface, e *podListerEntry) {
+	c.Lock()
+	defer c.Unlock()
+	if c.m[cs] != e {
+		return
+	}
+	delete(c.m, cs)
+	close(e.stop)
+}
+
+// systemPods returns the pods in kube-system, served from the informer cache of a WithSystemPodCache context when possible
+func systemPods(ctx context.Context, cs kubernetes.Interface) ([]core.Pod, error) {
+	if c := systemPodCache(ctx); c != nil {
+		cached, err := listCachedSystemPods(ctx, c, cs)
+		if err == nil {
+			return cached, nil
+		}
+		glog.Warningf("pod lister unavailable, listing directly: %v", err)
+	}
+
+	pods, err := cs.CoreV1().Pods("kube-system").List(meta.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// listCachedSystemPods lists the kube-system pods from the informer cache of c
+func listCachedSystemPods(ctx context.Context, c *podCache, cs kubernetes.Interface) ([]core.Pod, error) {
+	l, err := c.lister(ctx, cs)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := l.Pods("kube-system").List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]core.Pod, 0, len(cached))
+	for _, p := range cached {
+		pods = append(pods, *p)
+	}
+	return pods, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// podListServer serves an empty kube-system pod list, after unblock is closed if it is not nil
+func podListServer(unblock chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unblock != nil {
+			select {
+			case <-unblock:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if r.URL.Query().Get("watch") == "true" {
+			// send the headers, so that stopping the informer closes the connection
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{"resourceVersion":"1"},"items":[]}`)
+	}))
+}
+
+func TestSystemPodLister(t *testing.T) {
+	unblock := make(chan struct{})
+	slow := podListServer(unblock)
+	defer slow.Close()
+	fast := podListServer(nil)
+	defer fast.Close()
+
+	slowCS, err := NewRESTClientProvider(&rest.Config{Host: slow.URL}).Client()
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	fastCS, err := NewRESTClientProvider(&rest.Config{Host: fast.URL}).Client()
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+
+	pctx, release := WithSystemPodCache(context.Background())
+	c := systemPodCache(pctx)
+	slowDone := make(chan error)
+	go func() {
+		_, err := c.lister(pctx, slowCS)
+		slowDone <- err
+	}()
+
+	// a syncing informer must not block the listers of other clientsets
+	ctx, cancel := context.WithTimeout(pctx, 5*time.Second)
+	defer cancel()
+	if _, err := c.lister(ctx, fastCS); err != nil {
+		t.Errorf("lister(fast) error = %v, want it synced while another informer syncs", err)
+	}
+
+	close(unblock)
+	if err := <-slowDone; err != nil {
+		t.Errorf("lister(slow) error = %v", err)
+	}
+
+	entries := []*podListerEntry{}
+	for _, cs := range []*kubernetes.Clientset{slowCS, fastCS} {
+		c.Lock()
+		e := c.m[cs]
+		c.Unlock()
+		if e == nil {
+			t.Fatalf("no lister cached for %s", cs.RESTClient().Get().URL())
+		}
+		entries = append(entries, e)
+	}
+
+	release()
+	for _, e := range entries {
+		select {
+		case <-e.stop:
+		default:
+			t.Errorf("release() left an informer running")
+		}
+	}
+	if len(c.m) != 0 {
+		t.Errorf("release() left %d listers cached", len(c.m))
+	}
+
+	// without a cache in ctx, pods are listed directly and no informer is started
+	if _, err := systemPods(context.Background(), fastCS); err != nil {
+		t.Errorf("systemPods() error = %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForPodsRunning waits for at least minReady pods in ns matching selector to be Running
+func WaitForPodsRunning(ctx context.Context, cs kubernetes.Interface, ns string, selector labels.Selector, minReady int, timeout time.Duration) error {
+	glog.Infof("waiting for %d pods matching %q in %q to be running ...", minReady, selector, ns)
+	start := clk.Now()
+
+	running := 0
+	podsRunning := func() (bool, error) {
+		pods, err := cs.CoreV1().Pods(ns).List(meta.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			glog.Infof("temporary error listing pods matching %q: %v", selector, err)
+			observe(ctx, err.Error())
+			return false, nil
+		}
+		running = 0
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == core.PodRunning {
+				running++
+				continue
+			}
+			glog.Infof("waiting for %s", podStatusMsg(pod))
+		}
+		observe(ctx, fmt.Sprintf("%d of %d pods matching %q running", running, minReady, selector))
+		return running >= minReady, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, podsRunning); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "pods matching %q", selector)
+		}
+		return fmt.Errorf("only %d of %d pods matching %q in %q are running", running, minReady, selector, ns)
+	}
+	glog.Infof("duration metric: took %s for pods matching %q to be running ...", since(start), selector)
+	return nil
+}
+
+// WaitForPodDeleted waits for no pods in ns to match selector
+func WaitForPodDeleted(ctx context.Context, cs kubernetes.Interface, ns string, selector labels.Selector, timeout time.Duration) error {
+	glog.Infof("waiting for pods matching %q in %q to be deleted ...", selector, ns)
+	start := clk.Now()
+
+	remaining := []string{}
+	deleted := func() (bool, error) {
+		pods, err := cs.CoreV1().Pods(ns).List(meta.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			glog.Infof("temporary error listing pods matching %q: %v", selector, err)
+			return false, nil
+		}
+		remaining = []string{}
+		for _, pod := range pods.Items {
+			remaining = append(remaining, pod.ObjectMeta.GetName())
+		}
+		return len(remaining) == 0, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, deleted); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "pods matching %q", selector)
+		}
+		return fmt.Errorf("pods matching %q in %q were never deleted: %s", selector, ns, strings.Join(remaining, ", "))
+	}
+	glog.Infof("duration metric: took %s for pods matching %q to be deleted ...", since(start), selector)
+	return nil
+}
+
+// ParsePodWaitSpec parses a "namespace/selector" spec, as accepted by the --wait-pods flag
+func ParsePodWaitSpec(spec string) (string, labels.Selector, error) {
+	parts := strings.SplitN(spec, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", nil, fmt.Errorf("invalid pod wait spec %q, expected namespace/selector", spec)
+	}
+	selector, err := labels.Parse(parts[1])
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "parse selector %q", parts[1])
+	}
+	return parts[0], selector, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import "testing"
+
+func TestParsePodWaitSpec(t *testing.T) {
+	var tests = []struct {
+		spec     string
+		ns       string
+		selector string
+		wantErr  bool
+	}{
+		{"default/app=web", "default", "app=web", false},
+		{"test/app=web,tier in (frontend)", "test", "app=web,tier in (frontend)", false},
+		{"app=web", "", "", true},
+		{"default/", "", "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			ns, selector, err := ParsePodWaitSpec(tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParsePodWaitSpec(%q) error = %v, wantErr %v", tc.spec, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if ns != tc.ns || selector.String() != tc.selector {
+				t.Errorf("ParsePodWaitSpec(%q) = %q, %q, want %q, %q", tc.spec, ns, selector, tc.ns, tc.selector)
+			}
+		})
+	}
+}
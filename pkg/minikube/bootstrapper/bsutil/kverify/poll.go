@@ -0,0 +1,232 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	kconst "k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// Clock tells the time and sleeps, so that waits can be tested without waiting in real time
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Sleep pauses for d, returning ctx.Err() early if ctx is cancelled
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock is the Clock backed by the time package
+type realClock struct{}
+
+// Now returns the current time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses for d, returning ctx.Err() early if ctx is cancelled
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// clk is the Clock used by all waits in this package. Tests may replace it.
+var clk Clock = realClock{}
+
+// since returns the time elapsed since t, according to clk
+func since(t time.Time) time.Duration {
+	return clk.Now().Sub(t)
+}
+
+// Tunables for the backoff between poll attempts. The first attempt is retried after the retry interval,
+// and each following one waits PollFactor times longer, up to PollMaxInterval, plus up to PollJitter of random extra.
+var (
+	// PollMinInterval is the default retry interval, used when none is configured
+	PollMinInterval = kconst.APICallRetryInterval
+	// PollMaxInterval caps the interval between poll attempts
+	PollMaxInterval = 5 * time.Second
+	// PollFactor is how much the interval grows after each attempt
+	PollFactor = 1.5
+	// PollJitter is the maximum fraction of the interval to add at random, so that concurrent waits do not retry in lockstep
+	PollJitter = 0.2
+)
+
+// pollBackoff returns jittered, exponentially growing intervals starting from min
+type pollBackoff struct {
+	next time.Duration
+}
+
+// step returns the interval to wait before the next attempt
+func (b *pollBackoff) step() time.Duration {
+	d := b.next
+	if d > PollMaxInterval {
+		d = PollMaxInterval
+	}
+	b.next = time.Duration(float64(d) * PollFactor)
+	if PollJitter > 0 {
+		d = wait.Jitter(d, PollJitter)
+	}
+	return d
+}
+
+// pollImmediate is like wait.PollImmediate, but uses clk, backs off between attempts, and gives up as soon as ctx is cancelled
+func pollImmediate(ctx context.Context, interval time.Duration, timeout time.Duration, condition wait.ConditionFunc) error {
+	pStart := clk.Now()
+	deadline := pStart.Add(timeout)
+	b := &pollBackoff{next: interval}
+	progress := progressFunc(ctx)
+	for attempt := 1; ; attempt++ {
+		if progress != nil {
+			progress(componentName(ctx), attempt, since(pStart))
+		}
+		done, err := condition()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		now := clk.Now()
+		if !now.Before(deadline) {
+			return wait.ErrWaitTimeout
+		}
+		d := b.step()
+		if remaining := deadline.Sub(now); d > remaining {
+			d = remaining
+		}
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep pauses for d, returning ctx.Err() early if ctx is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	return clk.Sleep(ctx, d)
+}
+
+// retryIntervalKey is the context key for the base retry interval
+type retryIntervalKey struct{}
+
+// RetryInterval returns the base interval between API calls configured for cfg, defaulting to PollMinInterval
+func RetryInterval(cfg config.ClusterConfig) time.Duration {
+	if cfg.APICallRetryInterval > 0 {
+		return cfg.APICallRetryInterval
+	}
+	return PollMinInterval
+}
+
+// WithRetryInterval returns a copy of ctx which makes waits poll every d
+func WithRetryInterval(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, retryIntervalKey{}, d)
+}
+
+// retryInterval returns the base retry interval carried by ctx, defaulting to PollMinInterval
+func retryInterval(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(retryIntervalKey{}).(time.Duration); ok && d > 0 {
+		return d
+	}
+	return PollMinInterval
+}
+
+// ProgressFunc is called on each poll attempt while waiting for a component
+type ProgressFunc func(component string, attempt int, elapsed time.Duration)
+
+// progressKey is the context key for the ProgressFunc
+type progressKey struct{}
+
+// componentKey is the context key for the name of the component being waited for
+type componentKey struct{}
+
+// WithProgress returns a copy of ctx which makes waits call fn on each poll attempt
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFunc returns the ProgressFunc carried by ctx, or nil
+func progressFunc(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// withComponent returns a copy of ctx naming the component being waited for, for progress reporting
+func withComponent(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, componentKey{}, name)
+}
+
+// componentName returns the name of the component being waited for, or "" if unknown
+func componentName(ctx context.Context) string {
+	name, _ := ctx.Value(componentKey{}).(string)
+	return name
+}
+
+// ObservedState is the most recent state a wait saw for a component
+type ObservedState struct {
+	// Message describes what was seen, such as "2 of 3 pods running"
+	Message string `json:"message"`
+	// Time is when it was seen
+	Time time.Time `json:"time"`
+}
+
+// stateRecorderKey is the context key for the stateRecorder of a verification run
+type stateRecorderKey struct{}
+
+// stateRecorder holds the most recent state seen by the waits of one verification run for each component
+type stateRecorder struct {
+	sync.Mutex
+	m map[string]ObservedState
+}
+
+// withStateRecorder returns a copy of ctx which makes observe record into a new stateRecorder, along with the recorder
+func withStateRecorder(ctx context.Context) (context.Context, *stateRecorder) {
+	r := &stateRecorder{m: map[string]ObservedState{}}
+	return context.WithValue(ctx, stateRecorderKey{}, r), r
+}
+
+// observe records msg as the most recent state seen for the component being waited for, if ctx names one and carries a recorder
+func observe(ctx context.Context, msg string) {
+	name := componentName(ctx)
+	r, _ := ctx.Value(stateRecorderKey{}).(*stateRecorder)
+	if r == nil || name == "" || msg == "" {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.m[name] = ObservedState{Message: msg, Time: clk.Now()}
+}
+
+// states returns a copy of the most recent state recorded for each component
+func (r *stateRecorder) states() map[string]ObservedState {
+	r.Lock()
+	defer r.Unlock()
+	states := map[string]ObservedState{}
+	for k, v := range r.m {
+		states[k] = v
+	}
+	return states
+}
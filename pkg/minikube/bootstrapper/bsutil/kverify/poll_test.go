@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// fakeClock is a Clock whose Sleep advances time instantly
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.now = f.now.Add(d)
+	return nil
+}
+
+// useFakeClock replaces clk with a fakeClock, returning it and a func to restore the original
+func useFakeClock() (*fakeClock, func()) {
+	fc := &fakeClock{now: time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)}
+	orig := clk
+	clk = fc
+	return fc, func() { clk = orig }
+}
+
+// useBackoff sets the poll backoff tunables, returning a func to restore the originals
+func useBackoff(max time.Duration, factor float64, jitter float64) func() {
+	origMax, origFactor, origJitter := PollMaxInterval, PollFactor, PollJitter
+	PollMaxInterval, PollFactor, PollJitter = max, factor, jitter
+	return func() { PollMaxInterval, PollFactor, PollJitter = origMax, origFactor, origJitter }
+}
+
+func TestPollImmediateTimeout(t *testing.T) {
+	fc, restore := useFakeClock()
+	defer restore()
+	// constant intervals
+	defer useBackoff(time.Second, 1, 0)()
+	start := fc.Now()
+
+	calls := 0
+	err := pollImmediate(context.Background(), time.Second, time.Minute, func() (bool, error) {
+		calls++
+		return false, nil
+	})
+	if err != wait.ErrWaitTimeout {
+		t.Errorf("pollImmediate() = %v, want %v", err, wait.ErrWaitTimeout)
+	}
+	if calls != 61 {
+		t.Errorf("condition called %d times, want 61", calls)
+	}
+	if got := fc.Now().Sub(start); got != time.Minute {
+		t.Errorf("waited %s, want %s", got, time.Minute)
+	}
+}
+
+func TestPollImmediateBackoff(t *testing.T) {
+	fc, restore := useFakeClock()
+	defer restore()
+	defer useBackoff(8*time.Second, 2, 0)()
+	start := fc.Now()
+
+	// sleeps 1s, 2s, 4s, then 8s six times, then the remaining 5s
+	calls := 0
+	err := pollImmediate(context.Background(), time.Second, time.Minute, func() (bool, error) {
+		calls++
+		return false, nil
+	})
+	if err != wait.ErrWaitTimeout {
+		t.Errorf("pollImmediate() = %v, want %v", err, wait.ErrWaitTimeout)
+	}
+	if calls != 11 {
+		t.Errorf("condition called %d times, want 11", calls)
+	}
+	if got := fc.Now().Sub(start); got != time.Minute {
+		t.Errorf("waited %s, want %s", got, time.Minute)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	defer useBackoff(time.Minute, 1, 0.5)()
+	b := &pollBackoff{next: time.Second}
+	for i := 0; i < 100; i++ {
+		if d := b.step(); d < time.Second || d > 1500*time.Millisecond {
+			t.Fatalf("step() = %s, want between 1s and 1.5s", d)
+		}
+	}
+}
+
+func TestPollImmediateCancelled(t *testing.T) {
+	_, restore := useFakeClock()
+	defer restore()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	err := pollImmediate(ctx, time.Second, time.Minute, func() (bool, error) {
+		calls++
+		if calls == 3 {
+			cancel()
+		}
+		return false, nil
+	})
+	if err != context.Canceled {
+		t.Errorf("pollImmediate() = %v, want %v", err, context.Canceled)
+	}
+	if calls != 3 {
+		t.Errorf("condition called %d times, want 3", calls)
+	}
+}
+
+func TestPollImmediateProgress(t *testing.T) {
+	_, restore := useFakeClock()
+	defer restore()
+	defer useBackoff(time.Second, 1, 0)()
+
+	attempts := []int{}
+	elapsed := []time.Duration{}
+	ctx := WithProgress(withComponent(context.Background(), APIServerWaitKey), func(component string, attempt int, d time.Duration) {
+		if component != APIServerWaitKey {
+			t.Errorf("component = %q, want %q", component, APIServerWaitKey)
+		}
+		attempts = append(attempts, attempt)
+		elapsed = append(elapsed, d)
+	})
+
+	calls := 0
+	err := pollImmediate(ctx, time.Second, time.Minute, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil {
+		t.Fatalf("pollImmediate() = %v", err)
+	}
+	if fmt.Sprint(attempts) != "[1 2 3]" || fmt.Sprint(elapsed) != "[0s 1s 2s]" {
+		t.Errorf("progress called with attempts %v, elapsed %v, want [1 2 3], [0s 1s 2s]", attempts, elapsed)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	ctx, rec := withStateRecorder(context.Background())
+	apiserver := withComponent(ctx, "apiserver")
+	observe(apiserver, "connection refused")
+	observe(apiserver, "healthz returned 500")
+	observe(ctx, "ignored without a component")
+	observe(withComponent(ctx, "etcd"), "")
+	// another run records into its own recorder
+	other, otherRec := withStateRecorder(context.Background())
+	observe(withComponent(other, "apiserver"), "ok")
+	observe(withComponent(context.Background(), "apiserver"), "ignored without a recorder")
+
+	got := rec.states()
+	if len(got) != 1 {
+		t.Fatalf("states() = %v, want only apiserver", got)
+	}
+	if got["apiserver"].Message != "healthz returned 500" {
+		t.Errorf("states()[apiserver] = %q, want %q", got["apiserver"].Message, "healthz returned 500")
+	}
+	if msg := otherRec.states()["apiserver"].Message; msg != "ok" {
+		t.Errorf("other run states()[apiserver] = %q, want %q", msg, "ok")
+	}
+
+	got["apiserver"] = ObservedState{Message: "modified"}
+	if rec.states()["apiserver"].Message != "healthz returned 500" {
+		t.Errorf("states() returned a map sharing state with the recorder")
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// pressureConditions are the node conditions which make the kubelet evict pods
+var pressureConditions = []core.NodeConditionType{core.NodeMemoryPressure, core.NodeDiskPressure, core.NodePIDPressure}
+
+// nodePressure returns a *FatalProblemError if any node is under resource pressure, as the kubelet evicting system pods during startup keeps them restarting forever.
+// The error describes the machine resources and the eviction thresholds they were measured against, so that users learn the machine is too small.
+func nodePressure(cs kubernetes.Interface) error {
+	nodes, err := cs.CoreV1().Nodes().List(meta.ListOptions{})
+	if err != nil {
+		glog.Infof("unable to list nodes to check for pressure: %v", err)
+		return nil
+	}
+	for i := range nodes.Items {
+		n := &nodes.Items[i]
+		pressure := underPressure(n)
+		if len(pressure) == 0 {
+			continue
+		}
+		line := fmt.Sprintf("%s (node has %s memory, %s ephemeral storage", strings.Join(pressure, ", "), resourceCapacity(n, core.ResourceMemory), resourceCapacity(n, core.ResourceEphemeralStorage))
+		if th := evictionThresholds(cs, n.Name); th != "" {
+			line = fmt.Sprintf("%s; eviction thresholds: %s", line, th)
+		}
+		return &FatalProblemError{Source: fmt.Sprintf("node %q", n.Name), Line: line + ")"}
+	}
+	return nil
+}
+
+// underPressure returns a description of each pressure condition which is true for the node
+func underPressure(n *core.Node) []string {
+	found := []string{}
+	for _, want := range pressureConditions {
+		for _, c := range n.Status.Conditions {
+			if c.Type == want && c.Status == core.ConditionTrue {
+				found = append(found, fmt.Sprintf("%s: %s", c.Type, c.Message))
+			}
+		}
+	}
+	return found
+}
+
+// resourceCapacity returns the capacity of the node for a resource, or "unknown"
+func resourceCapacity(n *core.Node, r core.ResourceName) string {
+	q, ok := n.Status.Capacity[r]
+	if !ok {
+		return "unknown"
+	}
+	return q.String()
+}
+
+// kubeletConfigz is the part of the kubelet configz response used by diagnostics
+type kubeletConfigz struct {
+	KubeletConfig struct {
+		EvictionHard map[string]string `json:"evictionHard"`
+		MaxPods      int               `json:"maxPods"`
+	} `json:"kubeletconfig"`
+}
+
+// kubeletConfig returns the configuration the kubelet of a node runs with, read through the apiserver node proxy
+func kubeletConfig(cs kubernetes.Interface, nodeName string) (*kubeletConfigz, error) {
+	raw, err := cs.CoreV1().RESTClient().Get().AbsPath("/api/v1/nodes", nodeName, "proxy", "configz").DoRaw()
+	if err != nil {
+		return nil, errors.Wrapf(err, "read kubelet configz of %q", nodeName)
+	}
+	return parseKubeletConfigz(raw)
+}
+
+// parseKubeletConfigz parses a kubelet configz response
+func parseKubeletConfigz(raw []byte) (*kubeletConfigz, error) {
+	cz := &kubeletConfigz{}
+	if err := json.Unmarshal(raw, cz); err != nil {
+		return nil, errors.Wrap(err, "parse kubelet configz")
+	}
+	return cz, nil
+}
+
+// evictionThresholds returns the hard eviction thresholds the kubelet of a node runs with, or "" if they are unknown
+func evictionThresholds(cs kubernetes.Interface, nodeName string) string {
+	cz, err := kubeletConfig(cs, nodeName)
+	if err != nil {
+		glog.Infof("unable to get eviction thresholds: %v", err)
+		return ""
+	}
+	return formatEvictionThresholds(cz)
+}
+
+// formatEvictionThresholds returns the hard eviction thresholds in a kubelet configuration, formatted as in the kubelet flag
+func formatEvictionThresholds(cz *kubeletConfigz) string {
+	th := []string{}
+	for signal, v := range cz.KubeletConfig.EvictionHard {
+		th = append(th, fmt.Sprintf("%s<%s", signal, v))
+	}
+	sort.Strings(th)
+	return strings.Join(th, ", ")
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"strings"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+)
+
+func TestUnderPressure(t *testing.T) {
+	n := &core.Node{Status: core.NodeStatus{Conditions: []core.NodeCondition{
+		{Type: core.NodeReady, Status: core.ConditionTrue},
+		{Type: core.NodeMemoryPressure, Status: core.ConditionTrue, Message: "kubelet has insufficient memory available"},
+		{Type: core.NodeDiskPressure, Status: core.ConditionFalse, Message: "kubelet has no disk pressure"},
+	}}}
+	if got, want := strings.Join(underPressure(n), ","), "MemoryPressure: kubelet has insufficient memory available"; got != want {
+		t.Errorf("underPressure() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatEvictionThresholds(t *testing.T) {
+	raw := []byte(`{"kubeletconfig":{"cgroupDriver":"cgroupfs","evictionHard":{"memory.available":"100Mi","nodefs.available":"10%"}}}`)
+	cz, err := parseKubeletConfigz(raw)
+	if err != nil {
+		t.Fatalf("parseKubeletConfigz() error = %v", err)
+	}
+	if got, want := formatEvictionThresholds(cz), "memory.available<100Mi, nodefs.available<10%"; got != want {
+		t.Errorf("formatEvictionThresholds() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"k8s.io/minikube/pkg/minikube/bootstrapper"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/logs"
+	"k8s.io/minikube/pkg/minikube/out"
+)
+
+// ProblemOptions controls how problems found while waiting are reported
+type ProblemOptions struct {
+	// BackoffFactor is how many retry intervals to slow polling down by when problems are found. Zero disables the slow down.
+	BackoffFactor int
+	// MaxLines is the maximum number of lines to output per problem source
+	MaxLines int
+	// TailLines is how many lines back to search each log for problems. Zero uses logs.LookBackwardsCount.
+	TailLines int
+	// Quiet only logs problems, without outputting them to the console or slowing polling down
+	Quiet bool
+}
+
+// ProblemReporting is used by the wait functions when announcing problems. Override it to tune their behavior.
+var ProblemReporting = ProblemOptions{
+	BackoffFactor: 15,
+	MaxLines:      5,
+}
+
+// FatalProblemError is returned when a problem is found which waiting will not resolve
+type FatalProblemError struct {
+	// Source is where the problem was found, such as "kubelet"
+	Source string
+	// Line is the log line describing the problem
+	Line string
+}
+
+func (e *FatalProblemError) Error() string {
+	return fmt.Sprintf("fatal problem detected in %s: %s", e.Source, e.Line)
+}
+
+// isFatalProblem returns whether err is a *FatalProblemError
+func isFatalProblem(err error) bool {
+	_, ok := err.(*FatalProblemError)
+	return ok
+}
+
+// fatalProblem returns a *FatalProblemError for the latest line of the first source, by name, with fatal problems, or nil if there are none.
+// Sorting the sources reports the same one on every attempt, rather than whichever the map happens to yield first.
+func fatalProblem(fatal map[string][]string) error {
+	names := []string{}
+	for name, lines := range fatal {
+		if len(lines) > 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	lines := fatal[names[0]]
+	return &FatalProblemError{Source: names[0], Line: lines[len(lines)-1]}
+}
+
+// announceProblems checks for problems while waiting for component, and slows polling down if any are found.
+// It returns a *FatalProblemError if any of the problems will not resolve by waiting, or ctx.Err() if ctx is cancelled while slowed down.
+func announceProblems(ctx context.Context, r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr command.Runner, component string, opts ProblemOptions) error {
+	// a crash-looping runtime fails every other check confusingly
+	if err := VerifyRuntimeStable(cr, cfg.KubernetesConfig.ContainerRuntime); err != nil {
+		if isFatalProblem(err) {
+			return err
+		}
+		glog.Infof("unable to check runtime restarts: %v", err)
+	}
+
+	problems, fatal := logs.FindProblemsAndFatal(r, bs, cfg, cr, opts.TailLines)
+	if len(problems) == 0 {
+		return nil
+	}
+	if !opts.Quiet {
+		fresh := announcedProblems.filter(problems)
+		switch {
+		case len(fresh) > 0:
+			logs.OutputProblems(fresh, opts.MaxLines)
+			if component == APIServerWaitKey {
+				announceAPIServerContainer(cr)
+			}
+			announceKubeletHints(ctx, cr, cfg.KubernetesConfig.ContainerRuntime)
+			if hasAuthProblem(fresh) {
+				announceClockSkew(cr)
+			}
+		case announcedProblems.heartbeatDue():
+			out.T(out.Waiting, "Still waiting for {{.component}}, with the problems reported above ...", out.V{"component": component})
+		}
+	}
+	if err := fatalProblem(fatal); err != nil {
+		return err
+	}
+	if opts.Quiet {
+		// FindProblems has already logged each problem, and they are output again if the start fails
+		return nil
+	}
+	return sleep(ctx, RetryInterval(cfg)*time.Duration(opts.BackoffFactor))
+}
+
+// problemHeartbeat is how often to say that a wait is still going while no new problems are found
+const problemHeartbeat = 2 * time.Minute
+
+// problemTracker remembers which problems have been output, so that each wait cycle only outputs new ones
+type problemTracker struct {
+	sync.Mutex
+	seen map[string]bool
+	last time.Time
+}
+
+// announcedProblems tracks the problems output by announceProblems
+var announcedProblems = &problemTracker{}
+
+// filter returns the problems which have not been output before, and records them as output
+func (t *problemTracker) filter(problems map[string][]string) map[string][]string {
+	t.Lock()
+	defer t.Unlock()
+	if t.seen == nil {
+		t.seen = map[string]bool{}
+	}
+	fresh := map[string][]string{}
+	for name, lines := range problems {
+		for _, l := range lines {
+			sig := name + "\x00" + l
+			if t.seen[sig] {
+				continue
+			}
+			t.seen[sig] = true
+			fresh[name] = append(fresh[name], l)
+		}
+	}
+	if len(fresh) > 0 {
+		t.last = clk.Now()
+	}
+	return fresh
+}
+
+// heartbeatDue returns whether problemHeartbeat has passed since anything was output, and if so restarts the count
+func (t *problemTracker) heartbeatDue() bool {
+	t.Lock()
+	defer t.Unlock()
+	if since(t.last) < problemHeartbeat {
+		return false
+	}
+	t.last = clk.Now()
+	return true
+}
+
+// announceKubeletHints outputs the likely causes for a kubelet which is not running
+func announceKubeletHints(ctx context.Context, cr command.Runner, runtime string) {
+	st, err := KubeletStatus(ctx, cr)
+	if err == nil && st != state.Stopped {
+		return
+	}
+	for _, h := range PreflightKubelet(cr, runtime) {
+		out.WarningT("The kubelet is not running: {{.hint}}", out.V{"hint": h})
+	}
+}
+
+// announceClockSkew outputs whether the guest clock is skewed enough to cause authentication failures
+func announceClockSkew(cr command.Runner) {
+	if err := VerifyClockSkew(cr, DefaultMaxClockSkew); err != nil {
+		out.WarningT("Authentication failures may be caused by clock skew: {{.error}}", out.V{"error": err})
+	}
+}
+
+// announceAPIServerContainer outputs whether the apiserver container never started or is crash-looping
+func announceAPIServerContainer(cr command.Runner) {
+	ci, err := APIServerContainerInfo(cr)
+	if err == ErrContainerInfoUnavailable {
+		glog.Infof("skipping apiserver container check: %v", err)
+		return
+	}
+	if err != nil {
+		glog.Warningf("unable to get apiserver container info: %v", err)
+		out.WarningT("The apiserver container has not been started yet")
+		return
+	}
+	glog.Infof("apiserver container: %+v", ci)
+	if ci.RestartCount > 0 {
+		out.WarningT("The apiserver container has restarted {{.count}} times, most recently {{.ago}} ago ({{.state}})", out.V{"count": ci.RestartCount, "ago": since(ci.Created).Round(time.Second), "state": ci.State})
+	}
+}
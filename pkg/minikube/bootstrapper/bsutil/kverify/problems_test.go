@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestProblemTracker(t *testing.T) {
+	fc, restore := useFakeClock()
+	defer restore()
+
+	pt := &problemTracker{}
+	first := pt.filter(map[string][]string{"kubelet": {"a", "b"}})
+	if len(first["kubelet"]) != 2 {
+		t.Errorf("filter() = %v, want both problems on first sight", first)
+	}
+	second := pt.filter(map[string][]string{"kubelet": {"a", "b", "c"}, "etcd": {"a"}})
+	if strings.Join(second["kubelet"], ",") != "c" || strings.Join(second["etcd"], ",") != "a" {
+		t.Errorf("filter() = %v, want only kubelet c and etcd a", second)
+	}
+	if again := pt.filter(map[string][]string{"kubelet": {"a"}}); len(again) != 0 {
+		t.Errorf("filter() = %v, want no repeated problems", again)
+	}
+
+	if pt.heartbeatDue() {
+		t.Errorf("heartbeatDue() = true right after output")
+	}
+	fc.now = fc.now.Add(problemHeartbeat)
+	if !pt.heartbeatDue() {
+		t.Errorf("heartbeatDue() = false after %s", problemHeartbeat)
+	}
+	if pt.heartbeatDue() {
+		t.Errorf("heartbeatDue() = true twice in a row")
+	}
+}
+
+func TestFatalProblem(t *testing.T) {
+	var tests = []struct {
+		name  string
+		fatal map[string][]string
+		want  error
+	}{
+		{"none", nil, nil},
+		{"empty source", map[string][]string{"kubelet": {}}, nil},
+		{"latest line", map[string][]string{"kubelet": {"first", "second"}}, &FatalProblemError{Source: "kubelet", Line: "second"}},
+		{"first source", map[string][]string{"kubelet": {"k"}, "etcd": {"e"}, "kube-apiserver": {"a"}}, &FatalProblemError{Source: "etcd", Line: "e"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// maps are iterated in random order, so check the result is stable
+			for i := 0; i < 20; i++ {
+				got := fatalProblem(tc.fatal)
+				if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+					t.Fatalf("fatalProblem() = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultProxyMode is the mode kube-proxy uses on Linux when none is configured
+const defaultProxyMode = "iptables"
+
+// VerifyProxyMode checks if the mode in the kube-proxy configuration matches the expected
+func VerifyProxyMode(cs kubernetes.Interface, expected string) error {
+	cm, err := cs.CoreV1().ConfigMaps("kube-system").Get("kube-proxy", meta.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "get kube-proxy configmap")
+	}
+	mode, err := parseProxyMode(cm.Data["config.conf"])
+	if err != nil {
+		return err
+	}
+	glog.Infof("kube-proxy mode: %s", mode)
+	if expected == "" {
+		expected = defaultProxyMode
+	}
+	if mode != expected {
+		return fmt.Errorf("kube-proxy mode = %q, expected: %q", mode, expected)
+	}
+	return nil
+}
+
+// parseProxyMode returns the mode from a KubeProxyConfiguration document
+func parseProxyMode(conf string) (string, error) {
+	var kpc struct {
+		Mode string `yaml:"mode"`
+	}
+	if err := yaml.Unmarshal([]byte(conf), &kpc); err != nil {
+		return "", errors.Wrap(err, "parse kube-proxy config")
+	}
+	if kpc.Mode == "" {
+		return defaultProxyMode, nil
+	}
+	return kpc.Mode, nil
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import "testing"
+
+func TestParseProxyMode(t *testing.T) {
+	var tests = []struct {
+		name string
+		conf string
+		want string
+	}{
+		{"ipvs", "apiVersion: kubeproxy.config.k8s.io/v1alpha1\nkind: KubeProxyConfiguration\nmode: \"ipvs\"\n", "ipvs"},
+		{"unset", "apiVersion: kubeproxy.config.k8s.io/v1alpha1\nkind: KubeProxyConfiguration\nmode: \"\"\n", "iptables"},
+		{"empty", "", "iptables"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseProxyMode(tc.conf)
+			if err != nil {
+				t.Fatalf("parseProxyMode: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("parseProxyMode() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
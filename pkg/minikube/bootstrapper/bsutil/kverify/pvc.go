@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForPVCBound waits for a PersistentVolumeClaim to be Bound, returning its provisioning events if it never is
+func WaitForPVCBound(ctx context.Context, cs kubernetes.Interface, ns string, name string, timeout time.Duration) error {
+	glog.Infof("waiting for pvc %s/%s to be bound ...", ns, name)
+	start := clk.Now()
+
+	last := "pvc was never found"
+	bound := func() (bool, error) {
+		pvc, err := cs.CoreV1().PersistentVolumeClaims(ns).Get(name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting pvc %s/%s: %v", ns, name, err)
+			last = err.Error()
+			return false, nil
+		}
+		last = fmt.Sprintf("phase is %s", pvc.Status.Phase)
+		return pvc.Status.Phase == core.ClaimBound, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, bound); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "pvc %s/%s", ns, name)
+		}
+		return fmt.Errorf("pvc %s/%s was never bound: %s%s", ns, name, last, pvcEvents(cs, ns, name))
+	}
+	glog.Infof("duration metric: took %s for pvc %s/%s to be bound ...", since(start), ns, name)
+	return nil
+}
+
+// pvcEvents returns the events of a PersistentVolumeClaim, such as those of its provisioner, formatted for an error message
+func pvcEvents(cs kubernetes.Interface, ns string, name string) string {
+	sel := fmt.Sprintf("involvedObject.kind=PersistentVolumeClaim,involvedObject.name=%s", name)
+	evs, err := cs.CoreV1().Events(ns).List(meta.ListOptions{FieldSelector: sel})
+	if err != nil {
+		glog.Warningf("unable to list events of pvc %s/%s: %v", ns, name, err)
+		return ""
+	}
+	return formatEvents(evs.Items)
+}
+
+// formatEvents returns events oldest first, one per line, formatted for an error message
+func formatEvents(evs []core.Event) string {
+	sort.SliceStable(evs, func(i, j int) bool {
+		return evs[i].LastTimestamp.Before(&evs[j].LastTimestamp)
+	})
+	var sb strings.Builder
+	for _, ev := range evs {
+		fmt.Fprintf(&sb, "\n  %s %s: %s", ev.Type, ev.Reason, strings.TrimSpace(ev.Message))
+	}
+	return sb.String()
+}
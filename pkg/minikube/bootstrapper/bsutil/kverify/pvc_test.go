@@ -0,0 +1,38 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFormatEvents(t *testing.T) {
+	now := meta.Now()
+	earlier := meta.NewTime(now.Add(-time.Minute))
+	evs := []core.Event{
+		{Type: "Warning", Reason: "ProvisioningFailed", Message: "storageclass.storage.k8s.io \"fast\" not found\n", LastTimestamp: now},
+		{Type: "Normal", Reason: "ExternalProvisioning", Message: "waiting for a volume to be created", LastTimestamp: earlier},
+	}
+	want := "\n  Normal ExternalProvisioning: waiting for a volume to be created\n  Warning ProvisioningFailed: storageclass.storage.k8s.io \"fast\" not found"
+	if got := formatEvents(evs); got != want {
+		t.Errorf("formatEvents() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForResourceQuotaReady waits for the quota controller to have initialized a ResourceQuota's status, which it must before the quota is enforced
+func WaitForResourceQuotaReady(ctx context.Context, cs kubernetes.Interface, ns string, name string, timeout time.Duration) error {
+	glog.Infof("waiting for resourcequota %s/%s to be enforced ...", ns, name)
+	start := clk.Now()
+
+	last := "resourcequota was never found"
+	ready := func() (bool, error) {
+		q, err := cs.CoreV1().ResourceQuotas(ns).Get(name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting resourcequota %s/%s: %v", ns, name, err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := quotaReady(q)
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, ready); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "resourcequota %s/%s", ns, name)
+		}
+		return fmt.Errorf("resourcequota %s/%s was never enforced: %s", ns, name, last)
+	}
+	glog.Infof("duration metric: took %s for resourcequota %s/%s to be enforced ...", since(start), ns, name)
+	return nil
+}
+
+// quotaReady returns whether the status of a ResourceQuota has a hard and used value for every resource in its spec, and a description of what is missing
+func quotaReady(q *core.ResourceQuota) (bool, string) {
+	if len(q.Status.Hard) == 0 {
+		return false, "status.hard is not yet populated"
+	}
+	missing := []string{}
+	for r := range q.Spec.Hard {
+		_, hard := q.Status.Hard[r]
+		_, used := q.Status.Used[r]
+		if !hard || !used {
+			missing = append(missing, string(r))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return false, fmt.Sprintf("status is missing %s", strings.Join(missing, ", "))
+	}
+	return true, fmt.Sprintf("%d resources enforced", len(q.Status.Hard))
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestQuotaReady(t *testing.T) {
+	pods := core.ResourceList{core.ResourcePods: resource.MustParse("10")}
+	both := core.ResourceList{core.ResourcePods: resource.MustParse("10"), core.ResourceServices: resource.MustParse("5")}
+	var tests = []struct {
+		name   string
+		spec   core.ResourceList
+		status core.ResourceQuotaStatus
+		want   bool
+	}{
+		{"enforced", pods, core.ResourceQuotaStatus{Hard: pods, Used: core.ResourceList{core.ResourcePods: resource.MustParse("0")}}, true},
+		{"not initialized", pods, core.ResourceQuotaStatus{}, false},
+		{"no usage yet", pods, core.ResourceQuotaStatus{Hard: pods}, false},
+		{"spec updated", both, core.ResourceQuotaStatus{Hard: pods, Used: pods}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			q := &core.ResourceQuota{Spec: core.ResourceQuotaSpec{Hard: tc.spec}, Status: tc.status}
+			if got, msg := quotaReady(q); got != tc.want {
+				t.Errorf("quotaReady() = %v (%s), want %v", got, msg, tc.want)
+			}
+		})
+	}
+}
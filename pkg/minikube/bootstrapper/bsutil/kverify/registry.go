@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// registrySelector matches the pods of the registry addon, not including its proxy
+var registrySelector = labels.SelectorFromSet(labels.Set{"actual-registry": "true"})
+
+// VerifyRegistry waits for the registry addon pod to be running, and for its service to answer GET /v2/ with 200 from the node
+func VerifyRegistry(ctx context.Context, cs kubernetes.Interface, cr command.Runner, timeout time.Duration) error {
+	start := clk.Now()
+	if err := WaitForPodsRunning(ctx, cs, "kube-system", registrySelector, 1, timeout); err != nil {
+		return errors.Wrap(err, "registry pod")
+	}
+
+	glog.Infof("waiting for registry to serve /v2/ ...")
+	last := "service was never found"
+	serving := func() (bool, error) {
+		svc, err := cs.CoreV1().Services("kube-system").Get("registry", meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting registry service: %v", err)
+			last = err.Error()
+			return false, nil
+		}
+		url := fmt.Sprintf("http://%s/v2/", joinHostPort(svc.Spec.ClusterIP, 80))
+		rr, err := cr.RunCmd(exec.CommandContext(ctx, "curl", "-sS", "-o", "/dev/null", "-w", "%{http_code}", "--max-time", "5", url))
+		if err != nil {
+			glog.Infof("GET %s: %v", url, err)
+			last = err.Error()
+			return false, nil
+		}
+		code := strings.TrimSpace(rr.Stdout.String())
+		if code != "200" {
+			last = fmt.Sprintf("GET %s returned %s", url, code)
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout-since(start), serving); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "registry")
+		}
+		return fmt.Errorf("registry never served requests: %s", last)
+	}
+	glog.Infof("duration metric: took %s for the registry to serve requests ...", since(start))
+	return nil
+}
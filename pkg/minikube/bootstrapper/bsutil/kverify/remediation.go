@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/util"
+)
+
+// Remediation is a command which may bring a missing component back
+type Remediation struct {
+	// Component is the component the command is for
+	Component string
+	// Description says what the command does
+	Description string
+	// Command is the shell command line to run on the node
+	Command string
+}
+
+// Cmd returns the command to pass to a command.Runner
+func (r Remediation) Cmd() *exec.Cmd {
+	return exec.Command("/bin/bash", "-c", r.Command)
+}
+
+// staticPodComponents are the components run by the kubelet from manifests, which a kubelet restart may recover
+var staticPodComponents = map[string]bool{
+	"etcd":                    true,
+	"kube-apiserver":          true,
+	"kube-controller-manager": true,
+	"kube-scheduler":          true,
+}
+
+// Remediations returns the commands recommended to recover the missing components, in the order they should be run.
+// Components with no known remediation are left out.
+func Remediations(version string, missing []string) ([]Remediation, error) {
+	v, err := util.ParseKubernetesVersion(version)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse kubernetes version")
+	}
+	phase := "alpha"
+	controlPlane := "controlplane"
+	if v.GTE(semver.MustParse("1.13.0")) {
+		phase = "init"
+		controlPlane = "control-plane"
+	}
+	kubeadm := func(args string) string {
+		return fmt.Sprintf("%s %s phase %s --config %s", bsutil.InvokeKubeadm(version), phase, args, bsutil.KubeadmYamlPath)
+	}
+
+	rs := []Remediation{}
+	restarted := false
+	for _, c := range missing {
+		if staticPodComponents[c] && !restarted {
+			rs = append(rs, Remediation{Component: c, Description: "restart the kubelet, which runs the control plane static pods", Command: "sudo systemctl restart kubelet"})
+			restarted = true
+		}
+		switch c {
+		case "etcd":
+			rs = append(rs, Remediation{Component: c, Description: "regenerate the etcd static pod manifest", Command: kubeadm("etcd local")})
+		case "kube-apiserver":
+			rs = append(rs, Remediation{Component: c, Description: "regenerate the apiserver static pod manifest", Command: kubeadm(controlPlane + " apiserver")})
+		case "kube-controller-manager":
+			rs = append(rs, Remediation{Component: c, Description: "regenerate the controller-manager static pod manifest", Command: kubeadm(controlPlane + " controller-manager")})
+		case "kube-scheduler":
+			rs = append(rs, Remediation{Component: c, Description: "regenerate the scheduler static pod manifest", Command: kubeadm(controlPlane + " scheduler")})
+		case "kube-proxy":
+			rs = append(rs, Remediation{Component: c, Description: "reinstall the kube-proxy addon", Command: kubeadm("addon kube-proxy")})
+		case dnsComponent:
+			rs = append(rs, Remediation{Component: c, Description: "reinstall the cluster DNS addon", Command: kubeadm("addon coredns")})
+		}
+	}
+	return rs, nil
+}
+
+// RemediationsFor returns the Remediations for the components missing according to err, or none if err is not a *MissingComponentsError
+func RemediationsFor(version string, err error) ([]Remediation, error) {
+	var mce *MissingComponentsError
+	if !errors.As(err, &mce) {
+		return nil, nil
+	}
+	return Remediations(version, mce.Components)
+}
+
+// RunRemediations runs each remediation in order, stopping at the first to fail
+func RunRemediations(cr command.Runner, rs []Remediation) error {
+	for _, r := range rs {
+		if _, err := cr.RunCmd(r.Cmd()); err != nil {
+			return errors.Wrapf(err, "%s (%s)", r.Description, r.Component)
+		}
+	}
+	return nil
+}
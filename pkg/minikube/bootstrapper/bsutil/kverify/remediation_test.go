@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRemediationsFor(t *testing.T) {
+	err := fmt.Errorf("wait: %w", &MissingComponentsError{Components: []string{"kube-apiserver", "kube-scheduler", "kube-proxy", "storage-provisioner"}})
+	rs, rerr := RemediationsFor("v1.18.0", err)
+	if rerr != nil {
+		t.Fatalf("RemediationsFor() error = %v", rerr)
+	}
+	got := []string{}
+	for _, r := range rs {
+		got = append(got, r.Command)
+	}
+	kubeadm := "sudo env PATH=/var/lib/minikube/binaries/v1.18.0:$PATH kubeadm init phase"
+	want := []string{
+		"sudo systemctl restart kubelet",
+		kubeadm + " control-plane apiserver --config /var/tmp/minikube/kubeadm.yaml",
+		kubeadm + " control-plane scheduler --config /var/tmp/minikube/kubeadm.yaml",
+		kubeadm + " addon kube-proxy --config /var/tmp/minikube/kubeadm.yaml",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("RemediationsFor() = %q, want %q", got, want)
+	}
+
+	if rs, _ := RemediationsFor("v1.18.0", fmt.Errorf("timed out")); len(rs) != 0 {
+		t.Errorf("RemediationsFor(non-missing error) = %v, want none", rs)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// runnerRoot caches whether each runner already runs commands as root
+var runnerRoot = struct {
+	sync.Mutex
+	m map[command.Runner]bool
+}{m: map[command.Runner]bool{}}
+
+// runsAsRoot returns whether cr runs commands as root, such as the kic runner or the none driver run by root, so that they need no sudo.
+// The lock is not held while 'id -u' runs, so a slow runner does not hold up the others.
+func runsAsRoot(ctx context.Context, cr command.Runner) bool {
+	runnerRoot.Lock()
+	root, ok := runnerRoot.m[cr]
+	runnerRoot.Unlock()
+	if ok {
+		return root
+	}
+
+	rr, err := cr.RunCmd(exec.CommandContext(ctx, "id", "-u"))
+	if err != nil {
+		// assume the common case of an unprivileged ssh user, without caching, as the error may be transient
+		glog.Infof("unable to get runner uid, assuming sudo is required: %v", err)
+		return false
+	}
+	root = strings.TrimSpace(rr.Stdout.String()) == "0"
+	runnerRoot.Lock()
+	runnerRoot.m[cr] = root
+	runnerRoot.Unlock()
+	return root
+}
+
+// privilegedCommand returns a command which runs as root on cr, prefixed with sudo only if the runner needs it
+func privilegedCommand(ctx context.Context, cr command.Runner, name string, args ...string) *exec.Cmd {
+	if runsAsRoot(ctx, cr) {
+		return exec.CommandContext(ctx, name, args...)
+	}
+	return exec.CommandContext(ctx, "sudo", append([]string{name}, args...)...)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestPrivilegedCommand(t *testing.T) {
+	var tests = []struct {
+		name string
+		uid  map[string]string
+		want string
+	}{
+		{"ssh user", map[string]string{"id -u": "1000\n"}, "sudo systemctl is-active kubelet"},
+		{"root", map[string]string{"id -u": "0\n"}, "systemctl is-active kubelet"},
+		{"unknown", map[string]string{}, "sudo systemctl is-active kubelet"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(tc.uid)
+			c := privilegedCommand(context.Background(), cr, "systemctl", "is-active", "kubelet")
+			if got := strings.Join(c.Args, " "); got != tc.want {
+				t.Errorf("privilegedCommand() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// blockingRunner is a FakeCommandRunner whose commands block until unblock is closed
+type blockingRunner struct {
+	*command.FakeCommandRunner
+	unblock chan struct{}
+}
+
+func (b *blockingRunner) RunCmd(cmd *exec.Cmd) (*command.RunResult, error) {
+	<-b.unblock
+	return b.FakeCommandRunner.RunCmd(cmd)
+}
+
+func TestRunsAsRootDoesNotBlockOtherRunners(t *testing.T) {
+	slow := &blockingRunner{FakeCommandRunner: command.NewFakeCommandRunner(), unblock: make(chan struct{})}
+	slow.SetCommandToOutput(map[string]string{"id -u": "1000"})
+	fast := command.NewFakeCommandRunner()
+	fast.SetCommandToOutput(map[string]string{"id -u": "0"})
+
+	slowDone := make(chan bool)
+	go func() { slowDone <- runsAsRoot(context.Background(), slow) }()
+
+	fastDone := make(chan bool)
+	go func() { fastDone <- runsAsRoot(context.Background(), fast) }()
+	select {
+	case root := <-fastDone:
+		if !root {
+			t.Errorf("runsAsRoot(uid 0) = false, want true")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("runsAsRoot was blocked by another runner's id -u")
+	}
+
+	close(slow.unblock)
+	if root := <-slowDone; root {
+		t.Errorf("runsAsRoot(uid 1000) = true, want false")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// MaxRuntimeRestarts is how many times systemd may restart the container runtime before it is considered to be crash-looping
+var MaxRuntimeRestarts = 3
+
+// runtimeService returns the systemd service of a container runtime
+func runtimeService(runtime string) string {
+	switch strings.ToLower(runtime) {
+	case "crio", "cri-o":
+		return "crio"
+	case "containerd":
+		return "containerd"
+	default:
+		return "docker"
+	}
+}
+
+// VerifyRuntimeStable returns a *FatalProblemError if systemd has restarted the container runtime more than MaxRuntimeRestarts times,
+// as every pod check then fails in ways which look like missing components.
+func VerifyRuntimeStable(cr command.Runner, runtime string) error {
+	svc := runtimeService(runtime)
+	n, err := runtimeRestarts(cr, svc)
+	if err != nil {
+		return err
+	}
+	glog.Infof("%s has been restarted %d times", svc, n)
+	if n > MaxRuntimeRestarts {
+		return &FatalProblemError{Source: svc, Line: fmt.Sprintf("the container runtime is crash-looping: systemd has restarted %s.service %d times", svc, n)}
+	}
+	return nil
+}
+
+// runtimeRestarts returns how many times systemd has restarted a service
+func runtimeRestarts(cr command.Runner, svc string) (int, error) {
+	rr, err := cr.RunCmd(exec.Command("systemctl", "show", "--property=NRestarts", svc+".service"))
+	if err != nil {
+		return 0, errors.Wrapf(err, "systemctl show %s", svc)
+	}
+	return parseNRestarts(rr.Stdout.String())
+}
+
+// parseNRestarts parses the output of 'systemctl show --property=NRestarts'
+func parseNRestarts(out string) (int, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(out), "NRestarts=")
+	if v == "" {
+		// systemd before 235 does not count restarts
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Wrapf(err, "parse %q", out)
+	}
+	return n, nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestVerifyRuntimeStable(t *testing.T) {
+	var tests = []struct {
+		name      string
+		out       string
+		wantFatal bool
+	}{
+		{"stable", "NRestarts=0\n", false},
+		{"few restarts", "NRestarts=2\n", false},
+		{"crash looping", "NRestarts=12\n", true},
+		{"old systemd", "\n", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := command.NewFakeCommandRunner()
+			cr.SetCommandToOutput(map[string]string{"systemctl show --property=NRestarts containerd.service": tc.out})
+			err := VerifyRuntimeStable(cr, "containerd")
+			if isFatalProblem(err) != tc.wantFatal {
+				t.Errorf("VerifyRuntimeStable() = %v, want fatal %v", err, tc.wantFatal)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForSecretPopulated waits for a secret to exist with data under every key, as the token controller fills in service account token secrets after creating them
+func WaitForSecretPopulated(ctx context.Context, cs kubernetes.Interface, ns string, name string, timeout time.Duration) error {
+	glog.Infof("waiting for secret %s/%s to be populated ...", ns, name)
+	start := clk.Now()
+
+	last := "secret was never found"
+	populated := func() (bool, error) {
+		s, err := cs.CoreV1().Secrets(ns).Get(name, meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting secret %s/%s: %v", ns, name, err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := secretPopulated(s)
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, populated); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "secret %s/%s", ns, name)
+		}
+		return fmt.Errorf("secret %s/%s was never populated: %s", ns, name, last)
+	}
+	glog.Infof("duration metric: took %s for secret %s/%s to be populated ...", since(start), ns, name)
+	return nil
+}
+
+// secretPopulated returns whether a secret has data, with a non-empty value for every key, and a description of what is missing
+func secretPopulated(s *core.Secret) (bool, string) {
+	if len(s.Data) == 0 {
+		return false, "secret has no data"
+	}
+	empty := []string{}
+	for k, v := range s.Data {
+		if len(v) == 0 {
+			empty = append(empty, k)
+		}
+	}
+	if s.Type == core.SecretTypeServiceAccountToken && len(s.Data[core.ServiceAccountTokenKey]) == 0 {
+		empty = append(empty, core.ServiceAccountTokenKey)
+	}
+	if len(empty) > 0 {
+		sort.Strings(empty)
+		return false, fmt.Sprintf("empty keys: %s", strings.Join(empty, ", "))
+	}
+	return true, fmt.Sprintf("%d keys populated", len(s.Data))
+}
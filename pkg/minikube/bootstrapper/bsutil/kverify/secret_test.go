@@ -0,0 +1,44 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+)
+
+func TestSecretPopulated(t *testing.T) {
+	var tests = []struct {
+		name   string
+		secret core.Secret
+		want   bool
+	}{
+		{"token", core.Secret{Type: core.SecretTypeServiceAccountToken, Data: map[string][]byte{"token": []byte("eyJ"), "ca.crt": []byte("-----BEGIN")}}, true},
+		{"not yet populated", core.Secret{Type: core.SecretTypeServiceAccountToken}, false},
+		{"token missing", core.Secret{Type: core.SecretTypeServiceAccountToken, Data: map[string][]byte{"ca.crt": []byte("-----BEGIN")}}, false},
+		{"empty key", core.Secret{Data: map[string][]byte{"password": {}}}, false},
+		{"opaque", core.Secret{Data: map[string][]byte{"password": []byte("hunter2")}}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, msg := secretPopulated(&tc.secret); got != tc.want {
+				t.Errorf("secretPopulated() = %v (%s), want %v", got, msg, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// controlPlaneManifests are the static pod manifests kubeadm writes for a control plane node, by component
+var controlPlaneManifests = []string{"etcd", "kube-apiserver", "kube-controller-manager", "kube-scheduler"}
+
+// StaticPodsNotRunning returns the control plane components whose static pod manifest is missing, or which have no running container.
+// It only uses the runner and the container runtime, so it may be called before the apiserver is up.
+func StaticPodsNotRunning(r cruntime.Manager, cr command.Runner) ([]string, error) {
+	manifests, err := staticPodManifests(cr)
+	if err != nil {
+		return nil, err
+	}
+	notRunning := []string{}
+	for _, c := range controlPlaneManifests {
+		if !manifests[c] {
+			glog.Infof("no static pod manifest for %s in %s", c, vmpath.GuestManifestsDir)
+			notRunning = append(notRunning, c)
+			continue
+		}
+		ids, err := r.ListContainers(cruntime.ListOptions{State: cruntime.Running, Name: c})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list %s containers", c)
+		}
+		if len(ids) == 0 {
+			glog.Infof("static pod %s is not running", c)
+			notRunning = append(notRunning, c)
+		}
+	}
+	return notRunning, nil
+}
+
+// WaitForAllControlPlaneStaticPods waits for every control plane static pod manifest to have a running container
+func WaitForAllControlPlaneStaticPods(ctx context.Context, r cruntime.Manager, cr command.Runner, timeout time.Duration) error {
+	glog.Infof("waiting for control plane static pods to be running ...")
+	start := clk.Now()
+
+	last := "manifests were never listed"
+	running := func() (bool, error) {
+		notRunning, err := StaticPodsNotRunning(r, cr)
+		if err != nil {
+			last = err.Error()
+			return false, nil
+		}
+		if len(notRunning) > 0 {
+			last = fmt.Sprintf("not running: %s", strings.Join(notRunning, ", "))
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, running); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "static pods")
+		}
+		return fmt.Errorf("control plane static pods never started running: %s", last)
+	}
+	glog.Infof("duration metric: took %s for control plane static pods to be running ...", since(start))
+	return nil
+}
+
+// staticPodManifests returns the components which have a manifest in the static pod manifest directory
+func staticPodManifests(cr command.Runner) (map[string]bool, error) {
+	rr, err := cr.RunCmd(exec.Command("sudo", "ls", vmpath.GuestManifestsDir))
+	if err != nil {
+		return nil, errors.Wrapf(err, "list %s", vmpath.GuestManifestsDir)
+	}
+	return parseManifestNames(rr.Stdout.String()), nil
+}
+
+// parseManifestNames returns the components named by the manifest files listed in out
+func parseManifestNames(out string) map[string]bool {
+	names := map[string]bool{}
+	for _, f := range strings.Fields(out) {
+		ext := path.Ext(f)
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		names[strings.TrimSuffix(f, ext)] = true
+	}
+	return names
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+func TestStaticPodsNotRunning(t *testing.T) {
+	cr := command.NewFakeCommandRunner()
+	cr.SetCommandToOutput(map[string]string{
+		"sudo ls /etc/kubernetes/manifests":                                                            "etcd.yaml\nkube-apiserver.yaml\nkube-controller-manager.yaml\n",
+		"docker ps --filter status=running --filter=name=k8s_etcd --format={{.ID}}":                    "a1\n",
+		"docker ps --filter status=running --filter=name=k8s_kube-apiserver --format={{.ID}}":          "\n",
+		"docker ps --filter status=running --filter=name=k8s_kube-controller-manager --format={{.ID}}": "c3\n",
+		"docker ps --filter status=running --filter=name=k8s_kube-scheduler --format={{.ID}}":          "",
+	})
+	r, err := cruntime.New(cruntime.Config{Type: "docker", Runner: cr})
+	if err != nil {
+		t.Fatalf("cruntime.New: %v", err)
+	}
+	got, err := StaticPodsNotRunning(r, cr)
+	if err != nil {
+		t.Fatalf("StaticPodsNotRunning() error = %v", err)
+	}
+	if want := "kube-apiserver,kube-scheduler"; strings.Join(got, ",") != want {
+		t.Errorf("StaticPodsNotRunning() = %v, want %s", got, want)
+	}
+}
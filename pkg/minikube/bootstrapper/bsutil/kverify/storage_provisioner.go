@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForStorageProvisioner waits for the storage-provisioner pod to be Running with its containers Ready
+func WaitForStorageProvisioner(ctx context.Context, cs kubernetes.Interface, timeout time.Duration) error {
+	glog.Info("waiting for storage-provisioner to be ready ...")
+	start := clk.Now()
+
+	last := "pod was never found"
+	ready := func() (bool, error) {
+		pod, err := cs.CoreV1().Pods("kube-system").Get("storage-provisioner", meta.GetOptions{})
+		if err != nil {
+			glog.Infof("temporary error getting storage-provisioner: %v", err)
+			last = err.Error()
+			return false, nil
+		}
+		last = podStatusMsg(*pod)
+		return pod.Status.Phase == core.PodRunning && containersReady(*pod), nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, ready); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "storage-provisioner")
+		}
+		return fmt.Errorf("storage-provisioner never became ready: %s", last)
+	}
+	glog.Infof("duration metric: took %s for storage-provisioner to be ready ...", since(start))
+	return nil
+}
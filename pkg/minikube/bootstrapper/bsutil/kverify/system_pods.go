@@ -18,12 +18,14 @@ limitations under the License.
 package kverify
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	kconst "k8s.io/kubernetes/cmd/kubeadm/app/constants"
 	"k8s.io/minikube/pkg/minikube/bootstrapper"
@@ -32,39 +34,92 @@ import (
 	"k8s.io/minikube/pkg/minikube/cruntime"
 )
 
+// ImagePullError is returned when a control plane component is unable to pull its image
+type ImagePullError struct {
+	// Pod is the name of the pod whose image could not be pulled
+	Pod string
+	// Image is the image which could not be pulled
+	Image string
+	// Reason is the container waiting reason, such as ImagePullBackOff
+	Reason string
+}
+
+func (e *ImagePullError) Error() string {
+	return fmt.Sprintf("%s is unable to pull image %s: %s", e.Pod, e.Image, e.Reason)
+}
+
+// imagePullFailure returns an *ImagePullError for the first control plane pod unable to pull an image, or nil
+func imagePullFailure(pods []core.Pod) *ImagePullError {
+	for _, pod := range pods {
+		if pod.ObjectMeta.Labels["tier"] != "control-plane" {
+			continue
+		}
+		for _, c := range pod.Status.ContainerStatuses {
+			w := c.State.Waiting
+			if w == nil {
+				continue
+			}
+			if w.Reason == "ImagePullBackOff" || w.Reason == "ErrImagePull" {
+				return &ImagePullError{Pod: pod.ObjectMeta.GetName(), Image: c.Image, Reason: w.Reason}
+			}
+		}
+	}
+	return nil
+}
+
 // WaitForSystemPods verifies essential pods for running kurnetes is running
-func WaitForSystemPods(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr command.Runner, client *kubernetes.Clientset, start time.Time, timeout time.Duration) error {
+func WaitForSystemPods(ctx context.Context, r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr command.Runner, client kubernetes.Interface, start time.Time, timeout time.Duration) error {
 	glog.Info("waiting for kube-system pods to appear ...")
-	pStart := time.Now()
+	pStart := clk.Now()
 
 	podList := func() (bool, error) {
-		if time.Since(start) > timeout {
+		if since(start) > timeout {
 			return false, fmt.Errorf("cluster wait timed out during pod check")
 		}
-		if time.Since(start) > minLogCheckTime {
-			announceProblems(r, bs, cfg, cr)
-			time.Sleep(kconst.APICallRetryInterval * 5)
+		if since(start) > minLogCheckTime {
+			if err := announceProblems(ctx, r, bs, cfg, cr, SystemPodsWaitKey, ProblemReporting); err != nil {
+				return false, err
+			}
+			// pods evicted for lack of resources restart forever
+			if err := nodePressure(client); err != nil {
+				return false, err
+			}
+			if err := sleep(ctx, retryInterval(ctx)*5); err != nil {
+				return false, err
+			}
 		}
 
 		// Wait for any system pod, as waiting for apiserver may block until etcd
 		pods, err := client.CoreV1().Pods("kube-system").List(meta.ListOptions{})
 		if err != nil {
 			glog.Warningf("pod list returned error: %v", err)
+			observe(ctx, err.Error())
 			return false, nil
 		}
 		glog.Infof("%d kube-system pods found", len(pods.Items))
+		observe(ctx, fmt.Sprintf("%d kube-system pods found", len(pods.Items)))
 		for _, pod := range pods.Items {
 			glog.Infof(podStatusMsg(pod))
 		}
+		// waiting will not fix an image which can not be pulled
+		if ipe := imagePullFailure(pods.Items); ipe != nil {
+			return false, ipe
+		}
 
 		if len(pods.Items) < 2 {
 			return false, nil
 		}
 		return true, nil
 	}
-	if err := wait.PollImmediate(kconst.APICallRetryInterval, kconst.DefaultControlPlaneTimeout, podList); err != nil {
+	if err := pollImmediate(ctx, retryInterval(ctx), kconst.DefaultControlPlaneTimeout, podList); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "system pods")
+		}
+		if _, ok := err.(*ImagePullError); ok || isFatalProblem(err) {
+			return err
+		}
 		return fmt.Errorf("apiserver never returned a pod list")
 	}
-	glog.Infof("duration metric: took %s to wait for pod list to return data ...", time.Since(pStart))
+	glog.Infof("duration metric: took %s to wait for pod list to return data ...", since(pStart))
 	return nil
 }
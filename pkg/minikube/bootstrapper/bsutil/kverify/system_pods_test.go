@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImagePullFailure(t *testing.T) {
+	backoff := core.ContainerStatus{
+		Name:  "kube-apiserver",
+		Image: "k8s.gcr.io/kube-apiserver:v9.9.9",
+		State: core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+	}
+	var tests = []struct {
+		name string
+		pod  core.Pod
+		want string
+	}{
+		{"control plane backoff", core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: "kube-apiserver-minikube", Labels: map[string]string{"tier": "control-plane"}},
+			Status:     core.PodStatus{ContainerStatuses: []core.ContainerStatus{backoff}},
+		}, "kube-apiserver-minikube is unable to pull image k8s.gcr.io/kube-apiserver:v9.9.9: ImagePullBackOff"},
+		{"not control plane", core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: "app", Labels: map[string]string{"app": "app"}},
+			Status:     core.PodStatus{ContainerStatuses: []core.ContainerStatus{backoff}},
+		}, ""},
+		{"crashloop", core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: "etcd-minikube", Labels: map[string]string{"tier": "control-plane"}},
+			Status: core.PodStatus{ContainerStatuses: []core.ContainerStatus{
+				{Name: "etcd", State: core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			}},
+		}, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := imagePullFailure([]core.Pod{tc.pod})
+			if tc.want == "" {
+				if got != nil {
+					t.Errorf("imagePullFailure() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Error() != tc.want {
+				t.Errorf("imagePullFailure() = %v, want %q", got, tc.want)
+			}
+		})
+	}
+}
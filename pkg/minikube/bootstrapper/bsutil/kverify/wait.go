@@ -0,0 +1,416 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/minikube/pkg/minikube/bootstrapper"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/out"
+)
+
+// WaitReport records how long each component wait took
+type WaitReport struct {
+	// Durations maps each waited-for component key to how long its wait took
+	Durations map[string]time.Duration
+	// Failed is the key of the first component whose wait failed, if any
+	Failed string
+	// Errors maps each component key whose wait failed or was cancelled to its error
+	Errors map[string]error
+	// TimedOut records the component keys whose wait failed by running out of time
+	TimedOut map[string]bool
+	// Tolerated records the component keys whose wait failed, but which the FailurePolicy does not treat as fatal
+	Tolerated map[string]bool
+	// Skipped maps each component key whose check was unable to run, so whether it works is unknown, to why
+	Skipped map[string]error
+}
+
+// FailurePolicy maps component keys to whether a failure to wait for them is fatal. Components not in the policy are fatal.
+type FailurePolicy map[string]bool
+
+// fatal returns whether a failure to wait for the component is fatal
+func (p FailurePolicy) fatal(key string) bool {
+	if f, ok := p[key]; ok {
+		return f
+	}
+	return true
+}
+
+// failurePolicyKey is the context key for the FailurePolicy
+type failurePolicyKey struct{}
+
+// WithFailurePolicy returns a copy of ctx which makes WaitForCluster and WaitForComponents apply p
+func WithFailurePolicy(ctx context.Context, p FailurePolicy) context.Context {
+	return context.WithValue(ctx, failurePolicyKey{}, p)
+}
+
+// failurePolicy returns the FailurePolicy carried by ctx, or nil, under which every failure is fatal
+func failurePolicy(ctx context.Context) FailurePolicy {
+	p, _ := ctx.Value(failurePolicyKey{}).(FailurePolicy)
+	return p
+}
+
+// Component verification statuses used in a VerificationResult
+const (
+	VerifiedOK      = "ok"
+	VerifiedFailed  = "failed"
+	VerifiedTimeout = "timeout"
+	VerifiedSkipped = "skipped"
+)
+
+// ComponentResult is the outcome of verifying one component
+type ComponentResult struct {
+	Component string `json:"component"`
+	Status    string `json:"status"`
+	Duration  string `json:"duration"`
+	Error     string `json:"error,omitempty"`
+	// Tolerated is set if the component failed, but the FailurePolicy let verification carry on
+	Tolerated bool `json:"tolerated,omitempty"`
+	// LastObserved is the most recent state the wait saw before failing
+	LastObserved string `json:"last_observed,omitempty"`
+}
+
+// VerificationResult is the machine-readable outcome of WaitForCluster
+type VerificationResult struct {
+	Components []ComponentResult `json:"components"`
+	// States is the most recent state the waits saw for each component, such as to diagnose a timeout
+	States map[string]ObservedState `json:"states,omitempty"`
+}
+
+// JSON returns the result as JSON
+func (v *VerificationResult) JSON() ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// add appends the result for one component
+func (v *VerificationResult) add(component string, status string, d time.Duration, err error) {
+	cr := ComponentResult{Component: component, Status: status, Duration: d.Round(time.Millisecond).String()}
+	if err != nil {
+		cr.Error = err.Error()
+		cr.LastObserved = v.States[component].Message
+	}
+	v.Components = append(v.Components, cr)
+}
+
+// ShowVerificationResult shows the outcome of verifying each component, as text or json
+func ShowVerificationResult(res *VerificationResult, format string) error {
+	if format == "json" {
+		j, err := res.JSON()
+		if err != nil {
+			return errors.Wrap(err, "marshal verification result")
+		}
+		out.Ln("%s", j)
+		return nil
+	}
+
+	for _, c := range res.Components {
+		v := out.V{"component": c.Component, "duration": c.Duration, "error": c.Error}
+		switch {
+		case c.Status == VerifiedOK:
+			out.T(out.Check, "Verified {{.component}} in {{.duration}}", v)
+		case c.Status == VerifiedSkipped:
+			out.T(out.Option, "Skipped verifying {{.component}}", v)
+		case c.Tolerated:
+			out.T(out.Warning, "Could not verify {{.component}} ({{.error}}), continuing anyway", v)
+		case c.Status == VerifiedTimeout:
+			out.T(out.FailureType, "Timed out verifying {{.component}} after {{.duration}}: {{.error}}", v)
+		default:
+			out.T(out.FailureType, "Failed to verify {{.component}}: {{.error}}", v)
+		}
+	}
+	return nil
+}
+
+// BudgetExceededError is returned by WaitForCluster when the whole verification runs out of its time budget
+type BudgetExceededError struct {
+	// Budget is the time allowed for the whole verification
+	Budget time.Duration
+	// InFlight are the phases which were still being waited for when the budget ran out
+	InFlight []string
+}
+
+func (e *BudgetExceededError) Error() string {
+	if len(e.InFlight) == 0 {
+		return fmt.Sprintf("verification exceeded its %s budget", e.Budget)
+	}
+	return fmt.Sprintf("verification exceeded its %s budget while waiting for %s", e.Budget, strings.Join(e.InFlight, ", "))
+}
+
+// WaitForCluster waits for the kubelet, then concurrently for the enabled components: apiserver, system pods, default service account and storage provisioner.
+// It returns the first phase to fail fatally, according to the FailurePolicy carried by ctx, wrapped with its name.
+// The outcome of each phase, whether its failure was tolerated, and the states the waits last saw are returned.
+// If budget is positive, it caps the whole verification regardless of per-component timeouts, and running out of it returns a *BudgetExceededError.
+func WaitForCluster(ctx context.Context, bs bootstrapper.Bootstrapper, cs kubernetes.Interface, cr command.Runner, cfg config.ClusterConfig, components map[string]bool, budget time.Duration) (*VerificationResult, error) {
+	result := &VerificationResult{}
+	ctx, observed := withStateRecorder(ctx)
+
+	if !ShouldWait(components) {
+		glog.Infof("skip waiting for components based on config.")
+		return result, nil
+	}
+	start := clk.Now()
+	ctx = WithRetryInterval(ctx, RetryInterval(cfg))
+	// the informers serving kube-system pods live only as long as this verification
+	ctx, stopPodCache := WithSystemPodCache(ctx)
+	defer stopPodCache()
+	ctx = withEventStream(ctx)
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	kStart := clk.Now()
+	publishState(ctx, "kubelet", state.Starting, nil)
+	if err := WaitForKubeletHealthy(withComponent(ctx, "kubelet"), cr, WaitTimeout(cfg.WaitTimeouts, APIServerWaitKey)); err != nil {
+		publishResult(ctx, "kubelet", err, errors.Cause(err) == context.DeadlineExceeded)
+		result.States = observed.states()
+		result.add("kubelet", VerifiedFailed, since(kStart), err)
+		if budget > 0 && ctx.Err() == context.DeadlineExceeded {
+			return result, &BudgetExceededError{Budget: budget, InFlight: []string{"kubelet"}}
+		}
+		return result, errors.Wrap(err, "waiting for kubelet")
+	}
+	publishResult(ctx, "kubelet", nil, false)
+	result.add("kubelet", VerifiedOK, since(kStart), nil)
+
+	r, err := cruntime.New(cruntime.Config{Type: cfg.KubernetesConfig.ContainerRuntime, Runner: cr})
+	if err != nil {
+		return result, errors.Wrapf(err, "create runtime-manager %s", cfg.KubernetesConfig.ContainerRuntime)
+	}
+
+	cp, err := config.PrimaryControlPlane(&cfg)
+	if err != nil {
+		return result, errors.Wrap(err, "get primary control plane")
+	}
+	hostname, _, port, err := driver.ControlPaneEndpoint(&cfg, &cp, cfg.Driver)
+	if err != nil {
+		return result, errors.Wrap(err, "get control plane endpoint")
+	}
+
+	cfg.VerifyComponents = components
+	report, err := WaitForComponents(ctx, r, bs, cfg, cr, cs, hostname, port)
+	result.States = observed.states()
+	for _, key := range waitKeys() {
+		if !components[key] {
+			continue
+		}
+		d, waited := report.Durations[key]
+		failed := VerifiedFailed
+		if report.TimedOut[key] {
+			failed = VerifiedTimeout
+		}
+		switch {
+		case key == report.Failed:
+			result.add(key, failed, d, err)
+		case report.Tolerated[key]:
+			result.add(key, failed, d, report.Errors[key])
+			result.Components[len(result.Components)-1].Tolerated = true
+		case report.Errors[key] != nil:
+			// cancelled by the first failure
+			result.add(key, VerifiedSkipped, d, report.Errors[key])
+		case report.Skipped[key] != nil:
+			result.add(key, VerifiedSkipped, d, report.Skipped[key])
+		case waited:
+			result.add(key, VerifiedOK, d, nil)
+		default:
+			result.add(key, VerifiedSkipped, 0, nil)
+		}
+	}
+	if err != nil {
+		if budget > 0 && ctx.Err() == context.DeadlineExceeded {
+			inFlight := []string{}
+			for _, key := range waitKeys() {
+				if report.TimedOut[key] {
+					inFlight = append(inFlight, key)
+				}
+			}
+			return result, &BudgetExceededError{Budget: budget, InFlight: inFlight}
+		}
+		return result, err
+	}
+	glog.Infof("duration metric: took %s to wait for : %+v ...", since(start), components)
+	return result, nil
+}
+
+// WaitForComponents waits concurrently, up to MaxConcurrentWaits at once, for each component enabled in cfg.VerifyComponents, each within its own timeout, and reports how long each took.
+// The first component to fail cancels the waits for the rest.
+func WaitForComponents(ctx context.Context, r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr command.Runner, client kubernetes.Interface, hostname string, port int) (*WaitReport, error) {
+	report := &WaitReport{Durations: map[string]time.Duration{}, Errors: map[string]error{}, TimedOut: map[string]bool{}, Tolerated: map[string]bool{}, Skipped: map[string]error{}}
+	policy := failurePolicy(ctx)
+	if ctx.Value(eventStreamKey{}) == nil {
+		ctx = withEventStream(ctx)
+	}
+	var mu sync.Mutex
+
+	keys := []string{}
+	for _, key := range waitKeys() {
+		if cfg.VerifyComponents[key] {
+			keys = append(keys, key)
+		}
+	}
+	err := runLimited(ctx, keys, MaxConcurrentWaits, func(gctx context.Context, key string) error {
+		start := clk.Now()
+		timeout := WaitTimeout(cfg.WaitTimeouts, key)
+		ctx, cancel := context.WithTimeout(withComponent(gctx, key), timeout)
+		defer cancel()
+		publishState(ctx, key, state.Starting, nil)
+
+		var err error
+		switch key {
+		case APIServerWaitKey:
+			err = WaitForAPIServerProcess(ctx, r, bs, cfg, cr, start, timeout)
+			if err == nil {
+				err = WaitForHealthyAPIServer(ctx, r, bs, cfg, cr, client, start, hostname, port, timeout)
+			}
+			if err == nil {
+				err = WaitForAPIServerResponsive(ctx, client, timeout)
+			}
+			if err == nil {
+				// in-cluster clients reach the apiserver through the endpoints of the kubernetes service
+				err = WaitForServiceEndpoints(ctx, client, "default", "kubernetes", 1, timeout)
+			}
+			if err == nil {
+				// a changed host IP leaves the apiserver advertising an address clients can not reach
+				if cp, cerr := config.PrimaryControlPlane(&cfg); cerr == nil && cp.IP != "" {
+					err = WaitForAPIServerAddress(ctx, client, cp.IP, timeout)
+				}
+			}
+			if err == nil && cfg.KubernetesConfig.ExtraOptions.Get("audit-log-path", bsutil.Apiserver) != "" {
+				// confirm that audit flags passed through --extra-config took effect
+				err = VerifyAuditEnabled(cr)
+			}
+			if err == nil && cfg.KubernetesConfig.FeatureGates != "" {
+				// a misspelled gate is silently ignored by the apiserver
+				err = verifyFeatureGates(client, cfg)
+			}
+		case SystemPodsWaitKey:
+			err = WaitForSystemPods(ctx, r, bs, cfg, cr, client, start, timeout)
+		case DefaultSAWaitKey:
+			err = WaitForDefaultSA(ctx, client, timeout)
+		case StorageProvisionerWaitKey:
+			err = WaitForStorageProvisioner(ctx, client, timeout)
+		case NodeReadyWaitKey:
+			names := []string{}
+			for _, n := range cfg.Nodes {
+				names = append(names, bsutil.KubeNodeName(cfg, n))
+			}
+			err = WaitForNodesReady(ctx, client, names, timeout)
+			// a lone node must be untainted for workloads to schedule
+			if err == nil && len(cfg.Nodes) == 1 {
+				err = WaitForTaintRemoved(ctx, client, bsutil.KubeNodeName(cfg, cfg.Nodes[0]), controlPlaneTaint, timeout)
+			}
+			if err == nil {
+				warnPodHeadroom(client, names)
+			}
+		case DNSWaitKey:
+			// cluster DNS can not work until its pods are networked
+			err = VerifyCNI(ctx, client, timeout)
+			if err == nil {
+				err = WaitForCoreDNSReplicas(ctx, client, timeout)
+			}
+			if err == nil {
+				err = WaitForDNSFunctional(ctx, client, timeout)
+			}
+		case KubeProxyWaitKey:
+			err = WaitForKubeProxy(ctx, client, timeout)
+		case RegistryWaitKey:
+			err = VerifyRegistry(ctx, client, cr, timeout)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		report.Durations[key] = since(start)
+		if err != nil && checkSkipped(err) {
+			glog.Warningf("unable to verify %s: %v", key, err)
+			report.Skipped[key] = err
+			// whether the component works is unknown
+			publishState(ctx, key, state.None, err)
+			return nil
+		}
+		if err != nil {
+			report.Errors[key] = err
+			if errors.Cause(err) == context.DeadlineExceeded || report.Durations[key] >= timeout {
+				report.TimedOut[key] = true
+			}
+			publishResult(ctx, key, err, report.TimedOut[key])
+			// a tolerated failure must not cancel the other waits
+			if !policy.fatal(key) && gctx.Err() == nil {
+				glog.Warningf("tolerating failure to wait for %s: %v", key, err)
+				report.Tolerated[key] = true
+				return nil
+			}
+			// later errors are usually cancellations caused by the first failure
+			if report.Failed == "" {
+				report.Failed = key
+			}
+			return errors.Wrapf(err, "waiting for %s", key)
+		}
+		publishResult(ctx, key, nil, false)
+		glog.Infof("duration metric: took %s to wait for %s ...", report.Durations[key], key)
+		return nil
+	})
+	return report, err
+}
+
+// checkSkipped returns whether err means that a check was unable to run, rather than that the component failed it
+func checkSkipped(err error) bool {
+	return errors.Is(err, ErrDNSCheckUnavailable)
+}
+
+// MaxConcurrentWaits caps how many waits run at once, such as components or nodes, so that verifying a
+// multi-node cluster does not overwhelm the apiserver's rate limits or a loaded host. Values below 1 mean 1.
+var MaxConcurrentWaits = 4
+
+// runLimited calls fn for each item concurrently, with at most limit calls running at once.
+// The first call to fail cancels the context passed to the rest, and its error is returned.
+func runLimited(ctx context.Context, items []string, limit int, fn func(context.Context, string) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	g, gctx := errgroup.WithContext(ctx)
+	for _, item := range items {
+		item := item
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+			defer func() { <-sem }()
+			return fn(gctx, item)
+		})
+	}
+	return g.Wait()
+}
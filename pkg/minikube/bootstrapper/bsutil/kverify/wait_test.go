@@ -0,0 +1,282 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+func TestVerificationResultJSON(t *testing.T) {
+	v := &VerificationResult{}
+	v.add("kubelet", VerifiedOK, 1500*time.Millisecond, nil)
+	v.add(APIServerWaitKey, VerifiedFailed, time.Minute, fmt.Errorf("apiserver healthz never reported healthy"))
+	v.add(SystemPodsWaitKey, VerifiedSkipped, 0, nil)
+
+	got, err := v.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	want := `{"components":[{"component":"kubelet","status":"ok","duration":"1.5s"},{"component":"apiserver","status":"failed","duration":"1m0s","error":"apiserver healthz never reported healthy"},{"component":"system_pods","status":"skipped","duration":"0s"}]}`
+	if string(got) != want {
+		t.Errorf("JSON() = %s, want %s", got, want)
+	}
+}
+
+func TestShowVerificationResult(t *testing.T) {
+	res := &VerificationResult{Components: []ComponentResult{
+		{Component: "apiserver", Status: VerifiedOK, Duration: "1.2s"},
+		{Component: "system_pods", Status: VerifiedTimeout, Duration: "6m0s", Error: "missing components: kube-dns"},
+		{Component: "dns", Status: VerifiedFailed, Duration: "3s", Error: "nslookup failed", Tolerated: true},
+		{Component: "default_sa", Status: VerifiedSkipped, Duration: "0s"},
+	}}
+	cases := []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{
+			"Verified apiserver in 1.2s",
+			"Timed out verifying system_pods after 6m0s: missing components: kube-dns",
+			"Could not verify dns (nslookup failed), continuing anyway",
+			"Skipped verifying default_sa",
+		}},
+		{"json", []string{`{"components":[{"component":"apiserver","status":"ok","duration":"1.2s"}`}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			f := tests.NewFakeFile()
+			out.SetOutFile(f)
+			defer out.SetOutFile(os.Stdout)
+
+			if err := ShowVerificationResult(res, tc.format); err != nil {
+				t.Fatalf("ShowVerificationResult: %v", err)
+			}
+			got := f.String()
+			for _, w := range tc.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("ShowVerificationResult(%s) = %q, want it to contain %q", tc.format, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestRunLimited(t *testing.T) {
+	var mu sync.Mutex
+	running, most := 0, 0
+	items := []string{"m01", "m02", "m03", "m04", "m05"}
+	err := runLimited(context.Background(), items, 2, func(ctx context.Context, item string) error {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runLimited() error = %v", err)
+	}
+	if most > 2 {
+		t.Errorf("runLimited() ran %d at once, want at most 2", most)
+	}
+
+	err = runLimited(context.Background(), items, 0, func(ctx context.Context, item string) error {
+		if item == "m03" {
+			return fmt.Errorf("%s failed", item)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "m03 failed" {
+		t.Errorf("runLimited() error = %v, want m03 failed", err)
+	}
+}
+
+func TestFailurePolicy(t *testing.T) {
+	if p := failurePolicy(context.Background()); !p.fatal(DefaultSAWaitKey) {
+		t.Errorf("fatal() with no policy = false, want every failure fatal")
+	}
+	ctx := WithFailurePolicy(context.Background(), FailurePolicy{DefaultSAWaitKey: false, APIServerWaitKey: true})
+	p := failurePolicy(ctx)
+	if p.fatal(DefaultSAWaitKey) {
+		t.Errorf("fatal(%s) = true, want tolerated", DefaultSAWaitKey)
+	}
+	if !p.fatal(APIServerWaitKey) || !p.fatal(SystemPodsWaitKey) {
+		t.Errorf("fatal() = false for a component the policy does not tolerate")
+	}
+}
+
+func TestBudgetExceededError(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  *BudgetExceededError
+		want string
+	}{
+		{"in flight", &BudgetExceededError{Budget: 6 * time.Minute, InFlight: []string{"apiserver", "system_pods"}}, "verification exceeded its 6m0s budget while waiting for apiserver, system_pods"},
+		{"none in flight", &BudgetExceededError{Budget: time.Minute}, "verification exceeded its 1m0s budget"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.err.Error(); got != tc.want {
+				t.Errorf("Error() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// readyObjects returns the objects of a cluster whose default service account and storage-provisioner are ready
+func readyObjects() []runtime.Object {
+	return []runtime.Object{
+		&core.ServiceAccount{ObjectMeta: meta.ObjectMeta{Name: "default", Namespace: "default"}, Secrets: []core.ObjectReference{{Name: "default-token"}}},
+		&core.Secret{
+			ObjectMeta: meta.ObjectMeta{Name: "default-token", Namespace: "default"},
+			Type:       core.SecretTypeServiceAccountToken,
+			Data:       map[string][]byte{"token": []byte("eyJ"), "ca.crt": []byte("-----BEGIN")},
+		},
+		&core.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: "storage-provisioner", Namespace: "kube-system"},
+			Status:     core.PodStatus{Phase: core.PodRunning, ContainerStatuses: []core.ContainerStatus{{Name: "storage-provisioner", Ready: true}}},
+		},
+	}
+}
+
+func TestWaitForComponents(t *testing.T) {
+	_, restore := useFakeClock()
+	defer restore()
+
+	components := map[string]bool{DefaultSAWaitKey: true, StorageProvisionerWaitKey: true}
+	var tests = []struct {
+		name          string
+		objects       []runtime.Object
+		policy        FailurePolicy
+		wantErr       bool
+		wantFailed    string
+		wantTolerated bool
+	}{
+		{"ready", readyObjects(), nil, false, "", false},
+		{"missing", readyObjects()[:2], nil, true, StorageProvisionerWaitKey, false},
+		{"tolerated", readyObjects()[:2], FailurePolicy{StorageProvisionerWaitKey: false}, false, "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset(tc.objects...)
+			cfg := config.ClusterConfig{VerifyComponents: components, WaitTimeouts: map[string]time.Duration{StorageProvisionerWaitKey: time.Minute}}
+			ctx := WithFailurePolicy(context.Background(), tc.policy)
+
+			report, err := WaitForComponents(ctx, nil, nil, cfg, nil, cs, "192.168.39.10", 8443)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("WaitForComponents() error = %v, want error %v", err, tc.wantErr)
+			}
+			if report.Failed != tc.wantFailed {
+				t.Errorf("WaitForComponents() failed = %q, want %q", report.Failed, tc.wantFailed)
+			}
+			if report.Tolerated[StorageProvisionerWaitKey] != tc.wantTolerated {
+				t.Errorf("WaitForComponents() tolerated = %v, want %v", report.Tolerated, tc.wantTolerated)
+			}
+			if tc.wantErr || tc.wantTolerated {
+				if !report.TimedOut[StorageProvisionerWaitKey] {
+					t.Errorf("WaitForComponents() timed out = %v, want %s", report.TimedOut, StorageProvisionerWaitKey)
+				}
+			}
+			// a fatal failure may cancel the other wait
+			if _, ok := report.Durations[DefaultSAWaitKey]; !tc.wantErr && (!ok || report.Errors[DefaultSAWaitKey] != nil) {
+				t.Errorf("WaitForComponents() did not wait for %s: %v", DefaultSAWaitKey, report.Errors[DefaultSAWaitKey])
+			}
+		})
+	}
+}
+
+// componentResult returns the result for component, or an empty one if it was not verified
+func componentResult(res *VerificationResult, component string) ComponentResult {
+	for _, c := range res.Components {
+		if c.Component == component {
+			return c
+		}
+	}
+	return ComponentResult{}
+}
+
+func TestWaitForCluster(t *testing.T) {
+	_, restore := useFakeClock()
+	defer restore()
+
+	cr := command.NewFakeCommandRunner()
+	cr.SetCommandToOutput(map[string]string{
+		"sudo systemctl is-active kubelet":                     "active",
+		"curl -sS --max-time 5 http://localhost:10248/healthz": "ok",
+	})
+	cfg := config.ClusterConfig{
+		Driver:           "kvm2",
+		KubernetesConfig: config.KubernetesConfig{ContainerRuntime: "docker"},
+		Nodes:            []config.Node{{ControlPlane: true, IP: "192.168.39.10", Port: 8443}},
+		WaitTimeouts:     map[string]time.Duration{StorageProvisionerWaitKey: time.Minute},
+	}
+	components := map[string]bool{DefaultSAWaitKey: true, StorageProvisionerWaitKey: true}
+
+	// the storage-provisioner pod is missing, and tolerated so that every wait runs to completion
+	cs := fake.NewSimpleClientset(readyObjects()[:2]...)
+	ctx, events := WithVerificationWatcher(WithFailurePolicy(context.Background(), FailurePolicy{StorageProvisionerWaitKey: false}))
+	res, err := WaitForCluster(ctx, nil, cs, cr, cfg, components, 0)
+	if err != nil {
+		t.Fatalf("WaitForCluster() error = %v, want the storage-provisioner failure tolerated", err)
+	}
+	if !componentResult(res, StorageProvisionerWaitKey).Tolerated {
+		t.Errorf("WaitForCluster() %s = %+v, want it tolerated", StorageProvisionerWaitKey, componentResult(res, StorageProvisionerWaitKey))
+	}
+
+	want := map[string]string{"kubelet": VerifiedOK, DefaultSAWaitKey: VerifiedOK, StorageProvisionerWaitKey: VerifiedTimeout}
+	for key, status := range want {
+		if componentResult(res, key).Status != status {
+			t.Errorf("WaitForCluster() %s status = %q, want %q", key, componentResult(res, key).Status, status)
+		}
+	}
+	if msg := componentResult(res, StorageProvisionerWaitKey).LastObserved; !strings.Contains(msg, "not found") {
+		t.Errorf("WaitForCluster() %s last observed = %q, want the pod not found", StorageProvisionerWaitKey, msg)
+	}
+	if res.States[StorageProvisionerWaitKey].Message != componentResult(res, StorageProvisionerWaitKey).LastObserved {
+		t.Errorf("WaitForCluster() states = %v, want the last observed state of %s", res.States, StorageProvisionerWaitKey)
+	}
+
+	seen := map[string]bool{}
+	for len(events) > 0 {
+		ev := <-events
+		seen[ev.Component+" "+ev.New.String()] = true
+	}
+	for _, w := range []string{"kubelet Running", DefaultSAWaitKey + " Running", StorageProvisionerWaitKey + " Timeout"} {
+		if !seen[w] {
+			t.Errorf("WaitForCluster() events = %v, want %q", seen, w)
+		}
+	}
+}
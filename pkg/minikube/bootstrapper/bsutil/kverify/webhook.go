@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	admission "k8s.io/api/admissionregistration/v1beta1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// webhookBackend is the in-cluster service an admission webhook calls
+type webhookBackend struct {
+	// Webhook names the configuration and webhook, such as "validating ingress-nginx-admission/validate.nginx.ingress.kubernetes.io"
+	Webhook string
+	// Namespace is the namespace of the service
+	Namespace string
+	// Service is the name of the service
+	Service string
+	// Port is the service port
+	Port int32
+	// Path is the URL path the apiserver calls
+	Path string
+}
+
+// WaitForWebhooksReady waits for the service behind every registered admission webhook to have endpoints and respond.
+// A webhook whose backend is not ready makes every matching create fail, so it can leave the cluster unusable.
+func WaitForWebhooksReady(ctx context.Context, cs kubernetes.Interface, timeout time.Duration) error {
+	return waitForWebhooksReady(ctx, cs, "", timeout)
+}
+
+// WaitForAddonWebhooksReady is like WaitForWebhooksReady, but only waits for the webhooks the addon-manager deploys for addon,
+// so that a broken webhook of another addon or workload does not block enabling it. Addons with no labeled workloads are not checked.
+func WaitForAddonWebhooksReady(ctx context.Context, cs kubernetes.Interface, addon string, timeout time.Duration) error {
+	selector, ok := addonWebhookSelector(addon)
+	if !ok {
+		glog.Infof("addon %s has no labeled webhooks to verify", addon)
+		return nil
+	}
+	return waitForWebhooksReady(ctx, cs, selector, timeout)
+}
+
+// addonWebhookSelector returns the label selector matching the webhook configurations the addon-manager deploys for addon
+func addonWebhookSelector(addon string) (string, bool) {
+	value, ok := addonWorkloadLabels[addon]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s=%s,%s", addonLabel, value, addonManagerModeLabel), true
+}
+
+// waitForWebhooksReady waits for the backends of the webhook configurations matching selector, or every configuration if it is empty
+func waitForWebhooksReady(ctx context.Context, cs kubernetes.Interface, selector string, timeout time.Duration) error {
+	backends, err := webhookBackends(cs, selector)
+	if err != nil {
+		return err
+	}
+	for _, b := range backends {
+		if err := waitForWebhookReady(ctx, cs, b, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForWebhookReady waits for the service behind an admission webhook to have endpoints and respond
+func waitForWebhookReady(ctx context.Context, cs kubernetes.Interface, b webhookBackend, timeout time.Duration) error {
+	glog.Infof("waiting for webhook %s to be ready ...", b.Webhook)
+	start := clk.Now()
+
+	slices := endpointSlicesAvailable(cs)
+	last := "endpoints were never found"
+	ready := func() (bool, error) {
+		n, err := serviceReadyAddresses(cs, b.Namespace, b.Service, slices)
+		if err != nil {
+			glog.Infof("temporary error getting endpoints %s/%s: %v", b.Namespace, b.Service, err)
+			last = err.Error()
+			return false, nil
+		}
+		if n == 0 {
+			last = fmt.Sprintf("service %s/%s has no ready endpoints", b.Namespace, b.Service)
+			return false, nil
+		}
+		_, err = cs.CoreV1().Services(b.Namespace).ProxyGet("https", b.Service, strconv.Itoa(int(b.Port)), b.Path, nil).DoRaw()
+		if !webhookResponded(err) {
+			last = fmt.Sprintf("service %s/%s does not respond: %v", b.Namespace, b.Service, err)
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, ready); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "webhook %s", b.Webhook)
+		}
+		return fmt.Errorf("webhook %s never became ready: %s", b.Webhook, last)
+	}
+	glog.Infof("duration metric: took %s for webhook %s to be ready ...", since(start), b.Webhook)
+	return nil
+}
+
+// webhookResponded returns whether the result of calling a webhook through the apiserver proxy shows that it answered.
+// A webhook answers a bare GET with an error status, which still shows that it is serving.
+func webhookResponded(err error) bool {
+	if err == nil {
+		return true
+	}
+	status, ok := err.(apierr.APIStatus)
+	if !ok {
+		return false
+	}
+	switch status.Status().Code {
+	case 502, 503, 504:
+		// the proxy could not reach the service
+		return false
+	}
+	return true
+}
+
+// webhookBackends returns the services called by the validating and mutating admission webhooks whose configurations match selector. Webhooks called by URL are skipped.
+func webhookBackends(cs kubernetes.Interface, selector string) ([]webhookBackend, error) {
+	opts := meta.ListOptions{LabelSelector: selector}
+	backends := []webhookBackend{}
+	add := func(kind string, config string, webhook string, cc admission.WebhookClientConfig) {
+		if cc.Service == nil {
+			return
+		}
+		b := webhookBackend{
+			Webhook:   fmt.Sprintf("%s %s/%s", kind, config, webhook),
+			Namespace: cc.Service.Namespace,
+			Service:   cc.Service.Name,
+			Port:      443,
+			Path:      "/",
+		}
+		if cc.Service.Port != nil {
+			b.Port = *cc.Service.Port
+		}
+		if cc.Service.Path != nil {
+			b.Path = *cc.Service.Path
+		}
+		backends = append(backends, b)
+	}
+
+	vs, err := cs.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().List(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "list validating webhook configurations")
+	}
+	for _, c := range vs.Items {
+		for _, w := range c.Webhooks {
+			add("validating", c.Name, w.Name, w.ClientConfig)
+		}
+	}
+	ms, err := cs.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().List(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "list mutating webhook configurations")
+	}
+	for _, c := range ms.Items {
+		for _, w := range c.Webhooks {
+			add("mutating", c.Name, w.Name, w.ClientConfig)
+		}
+	}
+	return backends, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"errors"
+	"testing"
+
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestAddonWebhookSelector(t *testing.T) {
+	var tests = []struct {
+		addon  string
+		want   string
+		wantOK bool
+	}{
+		{"helm-tiller", "kubernetes.io/minikube-addons=helm,addonmanager.kubernetes.io/mode", true},
+		{"registry", "kubernetes.io/minikube-addons=registry,addonmanager.kubernetes.io/mode", true},
+		{"default-storageclass", "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.addon, func(t *testing.T) {
+			got, ok := addonWebhookSelector(tc.addon)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("addonWebhookSelector(%q) = %q, %v, want %q, %v", tc.addon, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestWebhookResponded(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"ok", nil, true},
+		{"bad request", apierr.NewBadRequest("expected AdmissionReview"), true},
+		{"unavailable", apierr.NewServiceUnavailable("no endpoints available for service"), false},
+		{"transport", errors.New("connection refused"), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := webhookResponded(tc.err); got != tc.want {
+				t.Errorf("webhookResponded(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kverify verifies a running kubernetes cluster is healthy
+package kverify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// kubeProxySelector matches the pods of the kube-proxy DaemonSet
+const kubeProxySelector = "k8s-app=kube-proxy"
+
+// WaitForWorkerNode runs only the node-local checks for a single node: its kubelet is active, and its kube-proxy pod is Running.
+// cr must be the runner of that node, and cs a client for the control plane it joined.
+func WaitForWorkerNode(ctx context.Context, cs kubernetes.Interface, cr command.Runner, nodeName string, timeout time.Duration) error {
+	glog.Infof("waiting for node-local components on %q ...", nodeName)
+	start := clk.Now()
+
+	if err := WaitForKubeletActive(ctx, cr, timeout); err != nil {
+		return errors.Wrapf(err, "node %q", nodeName)
+	}
+	if err := WaitForKubeProxyOnNode(ctx, cs, nodeName, timeout); err != nil {
+		return err
+	}
+	glog.Infof("duration metric: took %s for node-local components on %q ...", since(start), nodeName)
+	return nil
+}
+
+// WaitForKubeProxyOnNode waits for the kube-proxy pod scheduled to a node to be Running
+func WaitForKubeProxyOnNode(ctx context.Context, cs kubernetes.Interface, nodeName string, timeout time.Duration) error {
+	glog.Infof("waiting for kube-proxy on node %q to be running ...", nodeName)
+	start := clk.Now()
+
+	last := "kube-proxy pod was never found"
+	opts := meta.ListOptions{LabelSelector: kubeProxySelector, FieldSelector: "spec.nodeName=" + nodeName}
+	running := func() (bool, error) {
+		pods, err := cs.CoreV1().Pods("kube-system").List(opts)
+		if err != nil {
+			glog.Infof("temporary error listing kube-proxy pods: %v", err)
+			last = err.Error()
+			return false, nil
+		}
+		ok, msg := podRunningOnNode(pods.Items, nodeName)
+		last = msg
+		return ok, nil
+	}
+
+	if err := pollImmediate(ctx, retryInterval(ctx), timeout, running); err != nil {
+		observe(ctx, last)
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "kube-proxy on node %q", nodeName)
+		}
+		return fmt.Errorf("kube-proxy on node %q never became running: %s", nodeName, last)
+	}
+	glog.Infof("duration metric: took %s for kube-proxy on node %q to be running ...", since(start), nodeName)
+	return nil
+}
+
+// podRunningOnNode returns whether one of the pods is Running on the node, and a description of what was found
+func podRunningOnNode(pods []core.Pod, nodeName string) (bool, string) {
+	last := "no pod scheduled to the node"
+	for _, pod := range pods {
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+		if pod.Status.Phase == core.PodRunning {
+			return true, fmt.Sprintf("pod %q is Running", pod.Name)
+		}
+		last = fmt.Sprintf("pod %q is %s", pod.Name, pod.Status.Phase)
+	}
+	return false, last
+}
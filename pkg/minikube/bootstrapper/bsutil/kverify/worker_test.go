@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kverify
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodRunningOnNode(t *testing.T) {
+	pod := func(name string, node string, phase core.PodPhase) core.Pod {
+		return core.Pod{ObjectMeta: meta.ObjectMeta{Name: name}, Spec: core.PodSpec{NodeName: node}, Status: core.PodStatus{Phase: phase}}
+	}
+	var tests = []struct {
+		name string
+		pods []core.Pod
+		want bool
+	}{
+		{"running", []core.Pod{pod("kube-proxy-a", "minikube", core.PodRunning), pod("kube-proxy-b", "minikube-m02", core.PodRunning)}, true},
+		{"pending", []core.Pod{pod("kube-proxy-b", "minikube-m02", core.PodPending)}, false},
+		{"other node", []core.Pod{pod("kube-proxy-a", "minikube", core.PodRunning)}, false},
+		{"none", nil, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, msg := podRunningOnNode(tc.pods, "minikube-m02"); got != tc.want {
+				t.Errorf("podRunningOnNode() = %v (%s), want %v", got, msg, tc.want)
+			}
+		})
+	}
+}
@@ -39,7 +39,6 @@ import (
 	"k8s.io/client-go/kubernetes"
 	kconst "k8s.io/kubernetes/cmd/kubeadm/app/constants"
 	"k8s.io/minikube/pkg/drivers/kic"
-	"k8s.io/minikube/pkg/kapi"
 	"k8s.io/minikube/pkg/minikube/assets"
 	"k8s.io/minikube/pkg/minikube/bootstrapper"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
@@ -62,8 +61,9 @@ import (
 // Bootstrapper is a bootstrapper using kubeadm
 type Bootstrapper struct {
 	c           command.Runner
-	k8sClient   *kubernetes.Clientset // kubernetes client used to verify pods inside cluster
+	clients     *kverify.ClientProvider // provides the kubernetes client used to verify pods inside cluster
 	contextName string
+	verified    *kverify.VerificationResult // outcome of verifying components during the last WaitForNode
 }
 
 // NewBootstrapper creates a new kubeadm.Bootstrapper
@@ -78,7 +78,7 @@ func NewBootstrapper(api libmachine.API, cc config.ClusterConfig, n config.Node)
 	if err != nil {
 		return nil, errors.Wrap(err, "command runner")
 	}
-	return &Bootstrapper{c: runner, contextName: cc.Name, k8sClient: nil}, nil
+	return &Bootstrapper{c: runner, contextName: cc.Name}, nil
 }
 
 // GetKubeletStatus returns the kubelet status
@@ -228,6 +228,11 @@ func (k *Bootstrapper) init(cfg config.ClusterConfig) error {
 	c := exec.Command("/bin/bash", "-c", fmt.Sprintf("%s init --config %s %s --ignore-preflight-errors=%s",
 		bsutil.InvokeKubeadm(cfg.KubernetesConfig.KubernetesVersion), conf, extraFlags, strings.Join(ignore, ",")))
 	if _, err := k.c.RunCmd(c); err != nil {
+		if cr, cerr := cruntime.New(cruntime.Config{Type: cfg.KubernetesConfig.ContainerRuntime, Runner: k.c}); cerr == nil {
+			if notRunning, serr := kverify.StaticPodsNotRunning(cr, k.c); serr == nil && len(notRunning) > 0 {
+				glog.Infof("kubeadm init did not start the control plane: %v", notRunning)
+			}
+		}
 		return errors.Wrap(err, "run")
 	}
 
@@ -316,44 +321,36 @@ func (k *Bootstrapper) StartCluster(cfg config.ClusterConfig) error {
 }
 
 // client sets and returns a Kubernetes client to use to speak to a kubeadm launched apiserver
-func (k *Bootstrapper) client(ip string, port int) (*kubernetes.Clientset, error) {
-	if k.k8sClient != nil {
-		return k.k8sClient, nil
-	}
-
-	cc, err := kapi.ClientConfig(k.contextName)
-	if err != nil {
-		return nil, errors.Wrap(err, "client config")
-	}
-
-	endpoint := fmt.Sprintf("https://%s", net.JoinHostPort(ip, strconv.Itoa(port)))
-	if cc.Host != endpoint {
-		glog.Errorf("Overriding stale ClientConfig host %s with %s", cc.Host, endpoint)
-		cc.Host = endpoint
-	}
-	c, err := kubernetes.NewForConfig(cc)
-	if err == nil {
-		k.k8sClient = c
+func (k *Bootstrapper) client(cfg config.ClusterConfig, ip string, port int) (*kubernetes.Clientset, error) {
+	if k.clients == nil {
+		k.clients = kverify.NewClientProvider(cfg, ip, port)
 	}
-	return c, err
+	return k.clients.Client()
 }
 
 // WaitForNode blocks until the node appears to be healthy
 func (k *Bootstrapper) WaitForNode(cfg config.ClusterConfig, n config.Node, timeout time.Duration) error {
-	start := time.Now()
-
 	if !n.ControlPlane {
-		glog.Infof("%s is not a control plane, nothing to wait for", n.Name)
-		return nil
-	}
-	if !kverify.ShouldWait(cfg.VerifyComponents) {
-		glog.Infof("skip waiting for components based on config.")
-		return nil
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return k.waitForWorkerNode(ctx, cfg, n, timeout)
 	}
 
-	cr, err := cruntime.New(cruntime.Config{Type: cfg.KubernetesConfig.ContainerRuntime, Runner: k.c})
-	if err != nil {
-		return errors.Wrapf(err, "create runtme-manager %s", cfg.KubernetesConfig.ContainerRuntime)
+	// components without a --wait-timeouts entry get --wait-timeout, and as they are waited for
+	// concurrently, the whole wait is over once the longest of them has passed
+	cfg.WaitTimeouts = kverify.WaitTimeoutsWithFallback(cfg.WaitTimeouts, timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), kverify.LongestWaitTimeout(cfg.WaitTimeouts))
+	defer cancel()
+
+	if cfg.VerifyComponents[kverify.APIServerWaitKey] {
+		cr, err := cruntime.New(cruntime.Config{Type: cfg.KubernetesConfig.ContainerRuntime, Runner: k.c})
+		if err != nil {
+			return errors.Wrapf(err, "create runtime-manager %s", cfg.KubernetesConfig.ContainerRuntime)
+		}
+		// the client can't be trusted until the apiserver container is up
+		if err := kverify.WaitForAPIServerContainer(kverify.WithRetryInterval(ctx, kverify.RetryInterval(cfg)), cr, kverify.WaitTimeout(cfg.WaitTimeouts, kverify.APIServerWaitKey)); err != nil {
+			return errors.Wrap(err, "waiting for apiserver container")
+		}
 	}
 
 	hostname, _, port, err := driver.ControlPaneEndpoint(&cfg, &n, cfg.Driver)
@@ -361,45 +358,43 @@ func (k *Bootstrapper) WaitForNode(cfg config.ClusterConfig, n config.Node, time
 		return errors.Wrap(err, "get control plane endpoint")
 	}
 
-	if cfg.VerifyComponents[kverify.APIServerWaitKey] {
-		client, err := k.client(hostname, port)
-		if err != nil {
-			return errors.Wrap(err, "get k8s client")
-		}
-		if err := kverify.WaitForAPIServerProcess(cr, k, cfg, k.c, start, timeout); err != nil {
-			return errors.Wrap(err, "wait for apiserver proc")
-		}
-
-		if err := kverify.WaitForHealthyAPIServer(cr, k, cfg, k.c, client, start, hostname, port, timeout); err != nil {
-			return errors.Wrap(err, "wait for healthy API server")
-		}
+	client, err := k.client(cfg, hostname, port)
+	if err != nil {
+		return errors.Wrap(err, "get k8s client")
 	}
 
-	if cfg.VerifyComponents[kverify.SystemPodsWaitKey] {
-		client, err := k.client(hostname, port)
-		if err != nil {
-			return errors.Wrap(err, "get k8s client")
-		}
-		if err := kverify.WaitForSystemPods(cr, k, cfg, k.c, client, start, timeout); err != nil {
-			return errors.Wrap(err, "waiting for system pods")
-		}
+	k.verified, err = kverify.WaitForCluster(ctx, k, client, k.c, cfg, cfg.VerifyComponents, cfg.WaitBudget)
+	if err != nil {
+		k.suggestRemediations(client, cfg)
 	}
+	return err
+}
 
-	if cfg.VerifyComponents[kverify.DefaultSAWaitKey] {
-		client, err := k.client(hostname, port)
-		if err != nil {
-			return errors.Wrap(err, "get k8s client")
-		}
-		if err := kverify.WaitForDefaultSA(client, timeout); err != nil {
-			return errors.Wrap(err, "waiting for default service account")
-		}
+// VerificationResult returns the outcome of verifying components during the last WaitForNode call, or nil if it verified none
+func (k *Bootstrapper) VerificationResult() *kverify.VerificationResult {
+	return k.verified
+}
+
+// suggestRemediations prints the commands which may recover any expected components that are not running
+func (k *Bootstrapper) suggestRemediations(client kubernetes.Interface, cfg config.ClusterConfig) {
+	cerr := kverify.ExpectedComponentsRunningFor(context.Background(), client, cfg)
+	if cerr == nil {
+		return
+	}
+	rs, err := kverify.RemediationsFor(cfg.KubernetesConfig.KubernetesVersion, cerr)
+	if err != nil {
+		glog.Warningf("unable to suggest remediations: %v", err)
+		return
+	}
+	for _, r := range rs {
+		out.T(out.Tip, "To {{.description}}, try running on the node: {{.command}}", out.V{"description": r.Description, "command": r.Command})
 	}
-	glog.Infof("duration metric: took %s to wait for : %+v ...", time.Since(start), cfg.VerifyComponents)
-	return nil
 }
 
 // needsReset returns whether or not the cluster needs to be reconfigured
-func (k *Bootstrapper) needsReset(conf string, hostname string, port int, client *kubernetes.Clientset, version string) bool {
+func (k *Bootstrapper) needsReset(conf string, hostname string, port int, client kubernetes.Interface, cfg config.ClusterConfig) bool {
+	version := cfg.KubernetesConfig.KubernetesVersion
+
 	if rr, err := k.c.RunCmd(exec.Command("sudo", "diff", "-u", conf, conf+".new")); err != nil {
 		glog.Infof("needs reset: configs differ:\n%s", rr.Output())
 		return true
@@ -416,8 +411,13 @@ func (k *Bootstrapper) needsReset(conf string, hostname string, port int, client
 		return true
 	}
 
-	if err := kverify.ExpectedComponentsRunning(client); err != nil {
-		glog.Infof("needs reset: %v", err)
+	if err := kverify.ExpectedComponentsRunningFor(context.Background(), client, cfg); err != nil {
+		var mce *kverify.MissingComponentsError
+		if errors.As(err, &mce) {
+			glog.Infof("needs reset: %s", mce.Detail())
+		} else {
+			glog.Infof("needs reset: %v", err)
+		}
 		return true
 	}
 
@@ -464,14 +464,14 @@ func (k *Bootstrapper) restartCluster(cfg config.ClusterConfig) error {
 		return errors.Wrap(err, "control plane")
 	}
 
-	client, err := k.client(hostname, port)
+	client, err := k.client(cfg, hostname, port)
 	if err != nil {
 		return errors.Wrap(err, "getting k8s client")
 	}
 
 	// If the cluster is running, check if we have any work to do.
 	conf := bsutil.KubeadmYamlPath
-	if !k.needsReset(conf, hostname, port, client, cfg.KubernetesConfig.KubernetesVersion) {
+	if !k.needsReset(conf, hostname, port, client, cfg) {
 		glog.Infof("Taking a shortcut, as the cluster seems to be properly configured")
 		return nil
 	}
@@ -507,15 +507,16 @@ func (k *Bootstrapper) restartCluster(cfg config.ClusterConfig) error {
 	}
 
 	// We must ensure that the apiserver is healthy before proceeding
-	if err := kverify.WaitForAPIServerProcess(cr, k, cfg, k.c, time.Now(), kconst.DefaultControlPlaneTimeout); err != nil {
+	ctx := kverify.WithRetryInterval(context.Background(), kverify.RetryInterval(cfg))
+	if err := kverify.WaitForAPIServerProcess(ctx, cr, k, cfg, k.c, time.Now(), kconst.DefaultControlPlaneTimeout); err != nil {
 		return errors.Wrap(err, "apiserver healthz")
 	}
 
-	if err := kverify.WaitForHealthyAPIServer(cr, k, cfg, k.c, client, time.Now(), hostname, port, kconst.DefaultControlPlaneTimeout); err != nil {
+	if err := kverify.WaitForHealthyAPIServer(ctx, cr, k, cfg, k.c, client, time.Now(), hostname, port, kconst.DefaultControlPlaneTimeout); err != nil {
 		return errors.Wrap(err, "apiserver health")
 	}
 
-	if err := kverify.WaitForSystemPods(cr, k, cfg, k.c, client, time.Now(), kconst.DefaultControlPlaneTimeout); err != nil {
+	if err := kverify.WaitForSystemPods(ctx, cr, k, cfg, k.c, client, time.Now(), kconst.DefaultControlPlaneTimeout); err != nil {
 		return errors.Wrap(err, "system pods")
 	}
 
@@ -535,6 +536,25 @@ func (k *Bootstrapper) restartCluster(cfg config.ClusterConfig) error {
 	return nil
 }
 
+// waitForWorkerNode blocks until the node-local components of a worker node appear to be healthy, without re-checking the control plane
+func (k *Bootstrapper) waitForWorkerNode(ctx context.Context, cfg config.ClusterConfig, n config.Node, timeout time.Duration) error {
+	cp, err := config.PrimaryControlPlane(&cfg)
+	if err != nil {
+		return errors.Wrap(err, "get primary control plane")
+	}
+	hostname, _, port, err := driver.ControlPaneEndpoint(&cfg, &cp, cfg.Driver)
+	if err != nil {
+		return errors.Wrap(err, "get control plane endpoint")
+	}
+	client, err := k.client(cfg, hostname, port)
+	if err != nil {
+		return errors.Wrap(err, "get k8s client")
+	}
+
+	ctx = kverify.WithRetryInterval(ctx, kverify.RetryInterval(cfg))
+	return kverify.WaitForWorkerNode(ctx, client, k.c, bsutil.KubeNodeName(cfg, n), timeout)
+}
+
 // JoinCluster adds a node to an existing cluster
 func (k *Bootstrapper) JoinCluster(cc config.ClusterConfig, n config.Node, joinCmd string) error {
 	start := time.Now()
@@ -557,8 +577,41 @@ func (k *Bootstrapper) JoinCluster(cc config.ClusterConfig, n config.Node, joinC
 	return nil
 }
 
+// waitForJoinConfig waits for the ConfigMaps which joining nodes read their configuration from
+func (k *Bootstrapper) waitForJoinConfig(cc config.ClusterConfig) error {
+	cp, err := config.PrimaryControlPlane(&cc)
+	if err != nil {
+		return errors.Wrap(err, "get primary control plane")
+	}
+	hostname, _, port, err := driver.ControlPaneEndpoint(&cc, &cp, cc.Driver)
+	if err != nil {
+		return errors.Wrap(err, "get control plane endpoint")
+	}
+	client, err := k.client(cc, hostname, port)
+	if err != nil {
+		return errors.Wrap(err, "get k8s client")
+	}
+
+	version, err := util.ParseKubernetesVersion(cc.KubernetesConfig.KubernetesVersion)
+	if err != nil {
+		return errors.Wrap(err, "parsing kubernetes version")
+	}
+
+	ctx := kverify.WithRetryInterval(context.Background(), kverify.RetryInterval(cc))
+	for _, name := range []string{"kubeadm-config", fmt.Sprintf("kubelet-config-%d.%d", version.Major, version.Minor)} {
+		if err := kverify.WaitForConfigMap(ctx, client, "kube-system", name, kconst.DefaultControlPlaneTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GenerateToken creates a token and returns the appropriate kubeadm join command to run
 func (k *Bootstrapper) GenerateToken(cc config.ClusterConfig) (string, error) {
+	if err := k.waitForJoinConfig(cc); err != nil {
+		return "", errors.Wrap(err, "waiting for join config")
+	}
+
 	tokenCmd := exec.Command("/bin/bash", "-c", fmt.Sprintf("%s token create --print-join-command --ttl=0", bsutil.InvokeKubeadm(cc.KubernetesConfig.KubernetesVersion)))
 	r, err := k.c.RunCmd(tokenCmd)
 	if err != nil {
@@ -18,6 +18,7 @@ package config
 
 import (
 	"net"
+	"time"
 
 	"github.com/blang/semver"
 )
@@ -65,7 +66,11 @@ type ClusterConfig struct {
 	KubernetesConfig        KubernetesConfig
 	Nodes                   []Node
 	Addons                  map[string]bool
-	VerifyComponents        map[string]bool // map of components to verify and wait for after start.
+	VerifyComponents        map[string]bool          // map of components to verify and wait for after start.
+	WaitTimeouts            map[string]time.Duration // per-component timeouts for VerifyComponents, keyed by wait key.
+	WaitBudget              time.Duration            // cap on the whole verification. 0 means no cap.
+	APICallRetryInterval    time.Duration            // base interval between API calls while waiting. Defaults to kubeadm's.
+	ControlPlaneLabels      map[string]string        // label selectors identifying expected components in non-standard control planes. An empty selector skips the component.
 }
 
 // KubernetesConfig contains the parameters used to configure the VM Kubernetes.
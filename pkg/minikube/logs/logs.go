@@ -21,6 +21,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"regexp"
@@ -63,6 +64,29 @@ var rootCauses = []string{
 // rootCauseRe combines rootCauses into a single regex
 var rootCauseRe = regexp.MustCompile(strings.Join(rootCauses, "|"))
 
+// fatalCauses are regular expressions for problems which will not resolve by waiting longer
+var fatalCauses = []string{
+	`unknown flag: --`,
+	`failed to create listener`,
+	`address already in use`,
+	`pull access denied`,
+	`ImagePullBackOff.*(not found|no such image|manifest unknown)`,
+	`ErrImagePull.*(not found|no such image|manifest unknown)`,
+}
+
+// fatalCauseRe combines fatalCauses into a single regex
+var fatalCauseRe = regexp.MustCompile(strings.Join(fatalCauses, "|"))
+
+// Severity is how serious a problem is
+type Severity int
+
+const (
+	// Warning problems may resolve themselves while waiting
+	Warning Severity = iota
+	// Fatal problems will not resolve themselves, so there is no point in waiting
+	Fatal
+)
+
 // ignoreCauseRe is a regular expression that matches spurious errors to not surface
 var ignoreCauseRe = regexp.MustCompile("error: no objects passed to apply")
 
@@ -83,9 +107,13 @@ type logRunner interface {
 	RunCmd(*exec.Cmd) (*command.RunResult, error)
 }
 
-// lookbackwardsCount is how far back to look in a log for problems. This should be large enough to
+// LookBackwardsCount is how far back to look in a log for problems by default. This should be large enough to
 // include usage messages from a failed binary, but small enough to not include irrelevant problems.
-const lookBackwardsCount = 400
+const LookBackwardsCount = 400
+
+// FatalLookBackwardsCount is how far back from the end of a log a fatal problem is considered current. Lines further
+// back may have been left by a previous instance of a component, which has since restarted and may be healthy.
+const FatalLookBackwardsCount = 50
 
 // Follow follows logs from multiple files in tail(1) format
 func Follow(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr logRunner) error {
@@ -106,13 +134,44 @@ func Follow(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.Cluster
 
 // IsProblem returns whether this line matches a known problem
 func IsProblem(line string) bool {
-	return rootCauseRe.MatchString(line) && !ignoreCauseRe.MatchString(line)
+	return (rootCauseRe.MatchString(line) || fatalCauseRe.MatchString(line)) && !ignoreCauseRe.MatchString(line)
+}
+
+// ProblemSeverity returns the severity of a problem line
+func ProblemSeverity(line string) Severity {
+	if fatalCauseRe.MatchString(line) {
+		return Fatal
+	}
+	return Warning
+}
+
+// FatalProblems returns the subset of problems which are fatal
+func FatalProblems(problems map[string][]string) map[string][]string {
+	fatal := map[string][]string{}
+	for name, lines := range problems {
+		for _, l := range lines {
+			if ProblemSeverity(l) == Fatal {
+				fatal[name] = append(fatal[name], l)
+			}
+		}
+	}
+	return fatal
 }
 
-// FindProblems finds possible root causes among the logs
-func FindProblems(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr logRunner) map[string][]string {
+// FindProblems finds possible root causes among the last lines of each log, or LookBackwardsCount lines if lines is not positive
+func FindProblems(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr logRunner, lines int) map[string][]string {
+	problems, _ := FindProblemsAndFatal(r, bs, cfg, cr, lines)
+	return problems
+}
+
+// FindProblemsAndFatal is like FindProblems, but also returns the fatal problems found among the last FatalLookBackwardsCount lines of each log
+func FindProblemsAndFatal(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.ClusterConfig, cr logRunner, lines int) (map[string][]string, map[string][]string) {
+	if lines <= 0 {
+		lines = LookBackwardsCount
+	}
 	pMap := map[string][]string{}
-	cmds := logCommands(r, bs, cfg, lookBackwardsCount, false)
+	fMap := map[string][]string{}
+	cmds := logCommands(r, bs, cfg, lines, false)
 	for name := range cmds {
 		glog.Infof("Gathering logs for %s ...", name)
 		var b bytes.Buffer
@@ -124,20 +183,40 @@ func FindProblems(r cruntime.Manager, bs bootstrapper.Bootstrapper, cfg config.C
 			glog.Warningf("failed %s: command: %s %v output: %s", name, rr.Command(), err, rr.Output())
 			continue
 		}
-		scanner := bufio.NewScanner(&b)
-		problems := []string{}
-		for scanner.Scan() {
-			l := scanner.Text()
-			if IsProblem(l) {
-				glog.Warningf("Found %s problem: %s", name, l)
-				problems = append(problems, l)
-			}
+		problems, fatal := scanProblems(&b)
+		for _, l := range problems {
+			glog.Warningf("Found %s problem: %s", name, l)
 		}
 		if len(problems) > 0 {
 			pMap[name] = problems
 		}
+		if len(fatal) > 0 {
+			fMap[name] = fatal
+		}
+	}
+	return pMap, fMap
+}
+
+// scanProblems returns the problems in a log, and the fatal ones among its last FatalLookBackwardsCount lines
+func scanProblems(r io.Reader) ([]string, []string) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	problems := []string{}
+	fatal := []string{}
+	for i, l := range lines {
+		if !IsProblem(l) {
+			continue
+		}
+		problems = append(problems, l)
+		if ProblemSeverity(l) == Fatal && i >= len(lines)-FatalLookBackwardsCount {
+			fatal = append(fatal, l)
+		}
 	}
-	return pMap
+	return problems, fatal
 }
 
 // OutputProblems outputs discovered problems.
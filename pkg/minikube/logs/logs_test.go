@@ -17,6 +17,7 @@ limitations under the License.
 package logs
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -59,3 +60,63 @@ func TestIsProblem(t *testing.T) {
 		})
 	}
 }
+
+func TestProblemSeverity(t *testing.T) {
+	var tests = []struct {
+		name  string
+		want  Severity
+		input string
+	}{
+		{"unknown flag", Fatal, "F0212 14:55:46.443031    2693 server.go:148] unknown flag: --AllowedUnsafeSysctls"},
+		{"address in use", Fatal, "failed to listen on 0.0.0.0:8443: listen tcp 0.0.0.0:8443: bind: address already in use"},
+		{"missing image", Fatal, `Error syncing pod, skipping: failed to "StartContainer" for "app" with ImagePullBackOff: "Back-off pulling image \"example.com/app:nope\": manifest unknown"`},
+		{"bad certificate", Warning, "log.go:172] http: TLS handshake error from 127.0.0.1:49200: remote error: tls: bad certificate"},
+		{"crashloop", Warning, `failed to "StartContainer" for "kube-apiserver" with CrashLoopBackOff: "back-off 10s restarting failed container=kube-apiserver"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if !IsProblem(tc.input) {
+				t.Fatalf("IsProblem(%s)=false, want true", tc.input)
+			}
+			got := ProblemSeverity(tc.input)
+			if got != tc.want {
+				t.Fatalf("ProblemSeverity(%s)=%v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFatalProblems(t *testing.T) {
+	problems := map[string][]string{
+		"kubelet":        {"remote error: tls: bad certificate", "unknown flag: --foo"},
+		"kube-apiserver": {"remote error: tls: bad certificate"},
+	}
+	got := FatalProblems(problems)
+	if len(got) != 1 || len(got["kubelet"]) != 1 || got["kubelet"][0] != "unknown flag: --foo" {
+		t.Fatalf("FatalProblems()=%v, want only the kubelet unknown flag", got)
+	}
+}
+
+func TestScanProblems(t *testing.T) {
+	inUse := "listen tcp 0.0.0.0:8443: bind: address already in use"
+	healthy := strings.Repeat("Serving securely on [::]:8443\n", FatalLookBackwardsCount)
+	var tests = []struct {
+		name         string
+		log          string
+		wantProblems int
+		wantFatal    int
+	}{
+		{"recent", healthy + inUse + "\n", 1, 1},
+		{"stale", inUse + "\n" + healthy, 1, 0},
+		{"warning", "remote error: tls: bad certificate\n", 1, 0},
+		{"none", healthy, 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			problems, fatal := scanProblems(strings.NewReader(tc.log))
+			if len(problems) != tc.wantProblems || len(fatal) != tc.wantFatal {
+				t.Errorf("scanProblems() = %d problems, %d fatal, want %d, %d", len(problems), len(fatal), tc.wantProblems, tc.wantFatal)
+			}
+		})
+	}
+}
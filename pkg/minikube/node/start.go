@@ -58,6 +58,7 @@ import (
 
 const (
 	waitTimeout      = "wait-timeout"
+	verifyOutput     = "verify-output"
 	embedCerts       = "embed-certs"
 	keepContext      = "keep-context"
 	imageRepository  = "image-repository"
@@ -120,7 +121,7 @@ func Start(cc config.ClusterConfig, n config.Node, existingAddons map[string]boo
 		bs = setupKubeAdm(machineAPI, cc, n)
 		err = bs.StartCluster(cc)
 		if err != nil {
-			exit.WithLogEntries("Error starting cluster", err, logs.FindProblems(cr, bs, cc, mRunner))
+			exit.WithLogEntries("Error starting cluster", err, logs.FindProblems(cr, bs, cc, mRunner, kverify.ProblemReporting.TailLines))
 		}
 
 		// write the kubeconfig to the file system after everything required (like certs) are created by the bootstrapper
@@ -161,6 +162,7 @@ func Start(cc config.ClusterConfig, n config.Node, existingAddons map[string]boo
 			if err := bs.WaitForNode(cc, n, viper.GetDuration(waitTimeout)); err != nil {
 				return nil, errors.Wrap(err, "Wait failed")
 			}
+			showVerification(bs)
 		}
 	} else {
 		if err := bs.UpdateNode(cc, n, cr); err != nil {
@@ -184,6 +186,12 @@ func Start(cc config.ClusterConfig, n config.Node, existingAddons map[string]boo
 		if err = bs.JoinCluster(cc, n, joinCmd); err != nil {
 			return nil, errors.Wrap(err, "joining cluster")
 		}
+
+		if kverify.ShouldWait(cc.VerifyComponents) {
+			if err := bs.WaitForNode(cc, n, viper.GetDuration(waitTimeout)); err != nil {
+				return nil, errors.Wrap(err, "Wait failed")
+			}
+		}
 	}
 
 	return kcs, nil
@@ -230,6 +238,22 @@ func configureRuntimes(runner cruntime.CommandRunner, drvName string, k8s config
 	return cr
 }
 
+// verifier is a bootstrapper which keeps the outcome of verifying components during its last WaitForNode
+type verifier interface {
+	VerificationResult() *kverify.VerificationResult
+}
+
+// showVerification shows the outcome of the components verified by bs, in the format set by --verify-output
+func showVerification(bs bootstrapper.Bootstrapper) {
+	v, ok := bs.(verifier)
+	if !ok || v.VerificationResult() == nil {
+		return
+	}
+	if err := kverify.ShowVerificationResult(v.VerificationResult(), viper.GetString(verifyOutput)); err != nil {
+		glog.Warningf("unable to show verification result: %v", err)
+	}
+}
+
 // setupKubeAdm adds any requested files into the VM before Kubernetes is started
 func setupKubeAdm(mAPI libmachine.API, cfg config.ClusterConfig, n config.Node) bootstrapper.Bootstrapper {
 	bs, err := cluster.Bootstrapper(mAPI, viper.GetString(cmdcfg.Bootstrapper), cfg, n)